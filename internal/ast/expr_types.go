@@ -1,10 +1,15 @@
 package ast
 
-import "fracta/internal/token"
+import (
+	"fracta/internal/token"
+	"fracta/internal/types"
+	"go/constant"
+)
 
 type ExprBase struct {
-	Type Type
-	Line int
+	Type  types.Type // Set by sema
+	Span  token.Span
+	Const constant.Value // Exact value of an expression made of untyped constants, set by sema
 }
 
 type Literal struct {
@@ -18,11 +23,33 @@ func (e *Literal) ExprNode() *ExprBase { return &e.ExprBase }
 type Identifier struct {
 	ExprBase
 	Ident token.Token
+	Decl  ASTNode // Declaration the identifier refers to, set by sema
 }
 
 func (e *Identifier) node()               {}
 func (e *Identifier) ExprNode() *ExprBase { return &e.ExprBase }
 
+// Plain or compound assignment, evaluating to the stored value
+type Assignment struct {
+	ExprBase
+	Op     token.Token // '=' or a compound operator like '+='
+	Target Expression
+	Value  Expression
+}
+
+func (e *Assignment) node()               {}
+func (e *Assignment) ExprNode() *ExprBase { return &e.ExprBase }
+
+// 'value as T', an explicit conversion. The resolved target is the type of the expression.
+type Cast struct {
+	ExprBase
+	Value  Expression
+	Target Type
+}
+
+func (e *Cast) node()               {}
+func (e *Cast) ExprNode() *ExprBase { return &e.ExprBase }
+
 type Unary struct {
 	ExprBase
 	Op      token.Token
@@ -44,8 +71,9 @@ func (e *Binary) ExprNode() *ExprBase { return &e.ExprBase }
 
 type Call struct {
 	ExprBase
-	Callee Expression
-	Args   []Expression
+	Callee  Expression
+	Args    []Expression
+	Builtin string // Name of the builtin function called, like len, set by sema
 }
 
 func (e *Call) node()               {}
@@ -59,3 +87,23 @@ type Indexed struct {
 
 func (e *Indexed) node()               {}
 func (e *Indexed) ExprNode() *ExprBase { return &e.ExprBase }
+
+// 'value[low:high]', a slice of an array or of another slice. Missing bounds are nil.
+type Sliced struct {
+	ExprBase
+	Value Expression
+	Low   Expression
+	High  Expression
+}
+
+func (e *Sliced) node()               {}
+func (e *Sliced) ExprNode() *ExprBase { return &e.ExprBase }
+
+// '[a, b, c]', an array of the listed elements
+type ArrayLiteral struct {
+	ExprBase
+	Elems []Expression
+}
+
+func (e *ArrayLiteral) node()               {}
+func (e *ArrayLiteral) ExprNode() *ExprBase { return &e.ExprBase }
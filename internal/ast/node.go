@@ -14,6 +14,7 @@ type Statement interface {
 	StmtNode() *StmtBase
 }
 
+// A type as written in the source, sema resolves it to a types.Type
 type Type interface {
 	ASTNode
 	TypeNode()
@@ -1,22 +1,41 @@
 package ast
 
-import "fracta/internal/token"
+import (
+	"fracta/internal/token"
+	"fracta/internal/types"
+)
 
 type StmtBase struct {
-	Line int
+	Span token.Span
 }
 
 type FunctionDeclaration struct {
 	StmtBase
+	Doc        string // Doc comments written before the declaration
 	Name       token.Token
 	Args       []ArgPair
 	ReturnType Type
 	Body       Statement
+	Signature  *types.Function // Resolved parameter and return types, set by sema
 }
 
 func (s *FunctionDeclaration) node()               {}
 func (s *FunctionDeclaration) StmtNode() *StmtBase { return &s.StmtBase }
 
+// Declaration of a local with 'var' or 'let'
+type VariableDeclaration struct {
+	StmtBase
+	Doc     string // Doc comments written before the declaration
+	Mutable bool   // Declared with 'var', 'let' bindings cannot be assigned to
+	Name    token.Token
+	Type    Type       // Written type, if any
+	Value   Expression // Initializer, if any
+	VarType types.Type // Written type once resolved, or the one inferred from Value, set by sema
+}
+
+func (s *VariableDeclaration) node()               {}
+func (s *VariableDeclaration) StmtNode() *StmtBase { return &s.StmtBase }
+
 type ReturnStatement struct {
 	StmtBase
 	Value Expression
@@ -33,6 +52,69 @@ type ExpressionStatement struct {
 func (s *ExpressionStatement) node()               {}
 func (s *ExpressionStatement) StmtNode() *StmtBase { return &s.StmtBase }
 
+// An 'if' with its optional 'else' branch. An 'else if' chain nests in Else.
+type IfStatement struct {
+	StmtBase
+	Condition Expression
+	Then      *BlockStatement
+	Else      Statement // *BlockStatement, *IfStatement or nil
+}
+
+func (s *IfStatement) node()               {}
+func (s *IfStatement) StmtNode() *StmtBase { return &s.StmtBase }
+
+// 'while cond { }', the condition is checked before every iteration
+type WhileStatement struct {
+	StmtBase
+	Label     *token.Token // Optional label, for break and continue
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (s *WhileStatement) node()               {}
+func (s *WhileStatement) StmtNode() *StmtBase { return &s.StmtBase }
+
+// 'for init; cond; post { }', every part is optional. Locals declared by Init are scoped to the loop.
+type ForStatement struct {
+	StmtBase
+	Label     *token.Token
+	Init      Statement  // *VariableDeclaration, *ExpressionStatement or nil
+	Condition Expression // Loops forever when nil
+	Post      Expression // Evaluated after every iteration, continue included
+	Body      *BlockStatement
+}
+
+func (s *ForStatement) node()               {}
+func (s *ForStatement) StmtNode() *StmtBase { return &s.StmtBase }
+
+// 'loop { }', repeats until a break or return leaves it
+type LoopStatement struct {
+	StmtBase
+	Label *token.Token
+	Body  *BlockStatement
+}
+
+func (s *LoopStatement) node()               {}
+func (s *LoopStatement) StmtNode() *StmtBase { return &s.StmtBase }
+
+type BreakStatement struct {
+	StmtBase
+	Label  *token.Token // Targeted label, the innermost loop otherwise
+	Target Statement    // Loop left by the statement, set by sema
+}
+
+func (s *BreakStatement) node()               {}
+func (s *BreakStatement) StmtNode() *StmtBase { return &s.StmtBase }
+
+type ContinueStatement struct {
+	StmtBase
+	Label  *token.Token
+	Target Statement // Loop continued by the statement, set by sema
+}
+
+func (s *ContinueStatement) node()               {}
+func (s *ContinueStatement) StmtNode() *StmtBase { return &s.StmtBase }
+
 type BlockStatement struct {
 	StmtBase
 	Body []Statement
@@ -1,62 +1,58 @@
 package ast
 
-import (
-	"fmt"
-	"fracta/internal/token"
-	"strings"
-)
+import "fracta/internal/token"
 
-type UnkownType struct{}
+// A type written by name, like i32
+type TypeName struct {
+	Name token.Token
+}
 
-func (UnkownType) node()     {}
-func (UnkownType) TypeNode() {}
+func (*TypeName) node()     {}
+func (*TypeName) TypeNode() {}
 
-func (UnkownType) String() string {
-	return "<unknown>"
+func (t *TypeName) String() string {
+	return t.Name.Identifier
 }
 
-type BuiltinType struct {
-	Name string
+// '*T', a pointer to a value of type T
+type PointerType struct {
+	Star token.Token
+	Elem Type
 }
 
-func (*BuiltinType) node()     {}
-func (*BuiltinType) TypeNode() {}
+func (*PointerType) node()     {}
+func (*PointerType) TypeNode() {}
 
-func (b *BuiltinType) String() string {
-	return b.Name
+func (t *PointerType) String() string {
+	return "*" + t.Elem.String()
 }
 
-type NamedType struct {
-	Name token.Token
+// '[N]T', an array of N values of type T. N is a constant expression.
+type ArrayType struct {
+	Open token.Token
+	Len  Expression
+	Elem Type
 }
 
-func (*NamedType) node()     {}
-func (*NamedType) TypeNode() {}
+func (*ArrayType) node()     {}
+func (*ArrayType) TypeNode() {}
 
-func (n *NamedType) String() string {
-	return n.Name.String()
+func (t *ArrayType) String() string {
+	if lit, ok := t.Len.(*Literal); ok {
+		return "[" + lit.Value.Lexeme + "]" + t.Elem.String()
+	}
+	return "[...]" + t.Elem.String()
 }
 
-type FunctionType struct {
-	ReturnType Type
-	ArgTypes   []Type
+// '[]T', a slice of values of type T
+type SliceType struct {
+	Open token.Token
+	Elem Type
 }
 
-func (*FunctionType) node()     {}
-func (*FunctionType) TypeNode() {}
-
-func (f *FunctionType) String() string {
-	s := strings.Builder{}
-	_, _ = s.WriteString("func(")
-
-	if len(f.ArgTypes) != 0 {
-		for i := range len(f.ArgTypes) - 1 {
-			_, _ = fmt.Fprintf(&s, "%s, ", f.ArgTypes[i].String())
-		}
-		_, _ = s.WriteString(f.ArgTypes[len(f.ArgTypes)-1].String())
-	}
-
-	_, _ = fmt.Fprintf(&s, ") %s", f.ReturnType.String())
+func (*SliceType) node()     {}
+func (*SliceType) TypeNode() {}
 
-	return s.String()
+func (t *SliceType) String() string {
+	return "[]" + t.Elem.String()
 }
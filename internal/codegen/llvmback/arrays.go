@@ -0,0 +1,142 @@
+package llvmback
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/token"
+	"fracta/internal/types"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// Slices are lowered to a pointer to their first element and their length
+func (g *llvmGenerator) sliceType(t *types.Slice) llvm.Type {
+	return g.ctx.StructType([]llvm.Type{llvm.PointerType(g.llvmType(t.Elem()), 0), g.ctx.Int64Type()}, false)
+}
+
+func (g *llvmGenerator) generateArrayLiteral(e *ast.ArrayLiteral) llvm.Value {
+	v := llvm.Undef(g.llvmType(e.Type))
+	for i, el := range e.Elems {
+		v = g.bld.CreateInsertValue(v, g.generateExpression(el), i, "")
+	}
+	return v
+}
+
+// Returns the address of an array, storing it in a temporary when it is a value held nowhere
+func (g *llvmGenerator) arrayAddress(expr ast.Expression) llvm.Value {
+	switch e := expr.(type) {
+	case *ast.Identifier, *ast.Indexed:
+		return g.generatePlace(expr)
+	case *ast.Unary:
+		if e.Op.Kind == token.TokOpStar {
+			return g.generatePlace(expr)
+		}
+	}
+
+	v := g.generateExpression(expr)
+	slot := g.createEntryAlloca(v.Type(), "array.tmp")
+	g.bld.CreateStore(v, slot)
+	return slot
+}
+
+// Generates an index or slice bound, extended to the i64 used for addressing
+func (g *llvmGenerator) generateIndex(expr ast.Expression) llvm.Value {
+	v := g.generateExpression(expr)
+	i64 := g.ctx.Int64Type()
+
+	if types.IsSigned(expr.ExprNode().Type) && v.Type().IntTypeWidth() < 64 {
+		return g.bld.CreateSExt(v, i64, "")
+	}
+	return g.resizeUnsigned(v, i64)
+}
+
+// Returns the pointer to the first element and the length of an array or slice
+func (g *llvmGenerator) generateElements(expr ast.Expression) (llvm.Value, llvm.Value) {
+	switch t := types.Underlying(expr.ExprNode().Type).(type) {
+	case *types.Array:
+		zero := llvm.ConstInt(g.ctx.Int64Type(), 0, false)
+		data := g.bld.CreateInBoundsGEP(g.llvmType(t), g.arrayAddress(expr), []llvm.Value{zero, zero}, "")
+		return data, llvm.ConstInt(g.ctx.Int64Type(), uint64(t.Len()), false)
+	case *types.Slice:
+		s := g.generateExpression(expr)
+		return g.bld.CreateExtractValue(s, 0, ""), g.bld.CreateExtractValue(s, 1, "")
+	default:
+		panic(genPanic("cannot take the elements of a value of type %q", t.String()))
+	}
+}
+
+// Returns the address of an indexed element, stopping the program when the index is out of bounds
+func (g *llvmGenerator) elementAddress(e *ast.Indexed) llvm.Value {
+	data, length := g.generateElements(e.Indexee)
+	idx := g.generateIndex(e.Indices[0])
+
+	// Negative indices wrap to large unsigned ones, and are caught as well
+	g.checkBounds(g.bld.CreateICmp(llvm.IntULT, idx, length, ""))
+	return g.bld.CreateInBoundsGEP(g.llvmType(e.Type), data, []llvm.Value{idx}, "")
+}
+
+func (g *llvmGenerator) generateSlicedExpr(e *ast.Sliced) llvm.Value {
+	data, length := g.generateElements(e.Value)
+
+	low := llvm.ConstInt(g.ctx.Int64Type(), 0, false)
+	if e.Low != nil {
+		low = g.generateIndex(e.Low)
+	}
+
+	high := length
+	if e.High != nil {
+		high = g.generateIndex(e.High)
+	}
+
+	ordered := g.bld.CreateICmp(llvm.IntULE, low, high, "")
+	inside := g.bld.CreateICmp(llvm.IntULE, high, length, "")
+	g.checkBounds(g.bld.CreateAnd(ordered, inside, ""))
+
+	st := types.Underlying(e.Type).(*types.Slice)
+	first := g.bld.CreateInBoundsGEP(g.llvmType(st.Elem()), data, []llvm.Value{low}, "")
+
+	s := llvm.Undef(g.sliceType(st))
+	s = g.bld.CreateInsertValue(s, first, 0, "")
+	return g.bld.CreateInsertValue(s, g.bld.CreateSub(high, low, ""), 1, "")
+}
+
+// Continues in a new block when ok is true, and traps otherwise. Checks the builder could
+// fold to true, as for constant indices already checked by sema, emit nothing.
+func (g *llvmGenerator) checkBounds(ok llvm.Value) {
+	if c := ok.IsAConstantInt(); !c.IsNil() && c.ZExtValue() == 1 {
+		return
+	}
+
+	inBounds := g.ctx.AddBasicBlock(g.currentFunction, "bounds.ok")
+	outOfBounds := g.ctx.AddBasicBlock(g.currentFunction, "bounds.fail")
+	g.bld.CreateCondBr(ok, inBounds, outOfBounds)
+
+	g.bld.SetInsertPointAtEnd(outOfBounds)
+	g.bld.CreateCall(g.trapType(), g.trapFunction(), nil, "")
+	g.bld.CreateUnreachable()
+
+	g.bld.SetInsertPointAtEnd(inBounds)
+}
+
+func (g *llvmGenerator) trapType() llvm.Type {
+	return llvm.FunctionType(g.ctx.VoidType(), nil, false)
+}
+
+// Returns the llvm.trap intrinsic, declaring it on first use
+func (g *llvmGenerator) trapFunction() llvm.Value {
+	if fn := g.mod.NamedFunction("llvm.trap"); !fn.IsNil() {
+		return fn
+	}
+	return llvm.AddFunction(g.mod, "llvm.trap", g.trapType())
+}
+
+func (g *llvmGenerator) generateBuiltinCall(e *ast.Call) llvm.Value {
+	switch e.Builtin {
+	case "len":
+		// Sema folds the length of arrays unless the argument has side effects, which still
+		// happen here before the constant length is returned
+		_, length := g.generateElements(e.Args[0])
+		return length
+	default:
+		panic(genPanic("unknown builtin %q", e.Builtin))
+	}
+}
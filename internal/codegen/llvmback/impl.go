@@ -3,6 +3,9 @@ package llvmback
 import (
 	"errors"
 	"fracta/internal/ast"
+	"fracta/internal/token"
+	"fracta/internal/types"
+	"go/constant"
 	"io"
 
 	"tinygo.org/x/go-llvm"
@@ -32,5 +35,653 @@ func (g *llvmGenerator) Generate(ast ast.AST, w io.Writer) (e error) {
 			}
 		}
 	}()
-	panic("Not implemented")
+
+	for _, file := range ast {
+		g.declareFile(file)
+	}
+
+	for _, file := range ast {
+		g.generateFile(file)
+	}
+
+	if err := llvm.VerifyModule(g.mod, llvm.ReturnStatusAction); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, g.mod.String())
+	return err
+}
+
+func (g *llvmGenerator) declareFile(file *ast.FileSourceNode) {
+	for _, stmt := range file.Statements {
+		switch s := stmt.(type) {
+		case *ast.FunctionDeclaration:
+			g.declareFunction(s)
+		default:
+			panic(genPanic("invalid top level statement"))
+		}
+	}
+}
+
+func (g *llvmGenerator) declareFunction(fd *ast.FunctionDeclaration) {
+	name := fd.Name.Identifier
+
+	if !g.mod.NamedFunction(name).IsNil() {
+		panic(genPanic("function %q declared twice", name))
+	}
+
+	ftype := g.functionType(fd.Signature)
+	fn := llvm.AddFunction(g.mod, name, ftype)
+
+	for i, v := range fd.Args {
+		fn.Param(i).SetName(v.Name.Identifier)
+	}
+}
+
+func (g *llvmGenerator) generateFile(file *ast.FileSourceNode) {
+	for _, stmt := range file.Statements {
+		if fd, ok := stmt.(*ast.FunctionDeclaration); ok {
+			g.generateFunction(fd)
+		}
+	}
+}
+
+func (g *llvmGenerator) generateFunction(fd *ast.FunctionDeclaration) {
+	if fd.Body == nil {
+		// Body-less declarations stay as external declarations
+		return
+	}
+
+	fn := g.mod.NamedFunction(fd.Name.Identifier)
+	g.currentFunction = fn
+	g.locals = make(map[ast.ASTNode]llvm.Value)
+	g.loops = make(map[ast.Statement]loopBlocks)
+	defer func() {
+		g.currentFunction = llvm.Value{}
+		g.locals = nil
+		g.loops = nil
+	}()
+
+	entry := g.ctx.AddBasicBlock(fn, "entry")
+	g.bld.SetInsertPointAtEnd(entry)
+
+	// Parameters get a stack slot like any other local
+	for i := range fd.Args {
+		arg := &fd.Args[i]
+		slot := g.createEntryAlloca(g.llvmType(fd.Signature.Params()[i]), arg.Name.Identifier+".addr")
+		g.bld.CreateStore(fn.Param(i), slot)
+		g.locals[arg] = slot
+	}
+
+	g.generateStatement(fd.Body)
+
+	if !g.isTerminated() {
+		if fd.Signature.Result() == types.Void {
+			g.bld.CreateRetVoid()
+		} else {
+			// Sema rejects bodies whose end can be reached, so this block has no predecessors,
+			// as after an infinite loop
+			g.bld.CreateUnreachable()
+		}
+	}
+}
+
+// Reports whether the current insertion block already ends with a terminator
+func (g *llvmGenerator) isTerminated() bool {
+	last := g.bld.GetInsertBlock().LastInstruction()
+	if last.IsNil() {
+		return false
+	}
+
+	switch last.InstructionOpcode() {
+	case llvm.Ret, llvm.Br, llvm.Switch, llvm.IndirectBr, llvm.Unreachable:
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *llvmGenerator) generateStatement(st ast.Statement) {
+	switch s := st.(type) {
+	case *ast.BlockStatement:
+		g.generateBlockStatement(s)
+	case *ast.ReturnStatement:
+		g.generateReturnStatement(s)
+	case *ast.ExpressionStatement:
+		g.generateExpression(s.Expression)
+	case *ast.VariableDeclaration:
+		g.generateVariableDecl(s)
+	case *ast.IfStatement:
+		g.generateIfStatement(s)
+	case *ast.WhileStatement:
+		g.generateLoop(s, "while", s.Condition, nil, s.Body)
+	case *ast.ForStatement:
+		if s.Init != nil {
+			g.generateStatement(s.Init)
+		}
+		g.generateLoop(s, "for", s.Condition, s.Post, s.Body)
+	case *ast.LoopStatement:
+		g.generateLoop(s, "loop", nil, nil, s.Body)
+	case *ast.BreakStatement:
+		g.bld.CreateBr(g.loops[s.Target].breakBlock)
+	case *ast.ContinueStatement:
+		g.bld.CreateBr(g.loops[s.Target].continueBlock)
+	default:
+		panic(genPanic("unsupported statement"))
+	}
+}
+
+// Creates a stack slot at the start of the entry block, where LLVM can promote it to a register
+func (g *llvmGenerator) createEntryAlloca(t llvm.Type, name string) llvm.Value {
+	entry := g.currentFunction.EntryBasicBlock()
+
+	bld := g.ctx.NewBuilder()
+	defer bld.Dispose()
+
+	// After the slots created so far, so they stay in declaration order
+	inst := entry.FirstInstruction()
+	for !inst.IsNil() && inst.InstructionOpcode() == llvm.Alloca {
+		inst = llvm.NextInstruction(inst)
+	}
+
+	if inst.IsNil() {
+		bld.SetInsertPointAtEnd(entry)
+	} else {
+		bld.SetInsertPointBefore(inst)
+	}
+
+	return bld.CreateAlloca(t, name)
+}
+
+func (g *llvmGenerator) generateVariableDecl(vd *ast.VariableDeclaration) {
+	t := g.llvmType(vd.VarType)
+	slot := g.createEntryAlloca(t, vd.Name.Identifier)
+	g.locals[vd] = slot
+
+	// Variables without an initializer start as zero
+	value := llvm.ConstNull(t)
+	if vd.Value != nil {
+		value = g.generateExpression(vd.Value)
+	}
+
+	g.bld.CreateStore(value, slot)
+}
+
+func (g *llvmGenerator) generateBlockStatement(bl *ast.BlockStatement) {
+	for _, st := range bl.Body {
+		if g.isTerminated() {
+			// Anything after a terminator is unreachable
+			return
+		}
+		g.generateStatement(st)
+	}
+}
+
+func (g *llvmGenerator) generateIfStatement(st *ast.IfStatement) {
+	cond := g.generateExpression(st.Condition)
+
+	thenBlock := g.ctx.AddBasicBlock(g.currentFunction, "if.then")
+	elseBlock := g.ctx.AddBasicBlock(g.currentFunction, "if.else")
+	g.bld.CreateCondBr(cond, thenBlock, elseBlock)
+
+	// Created once a branch falls through, when every branch exits there is nothing after the 'if'
+	var endBlock llvm.BasicBlock
+	fallThrough := func() {
+		if g.isTerminated() {
+			return
+		}
+		if endBlock.IsNil() {
+			endBlock = g.ctx.AddBasicBlock(g.currentFunction, "if.end")
+		}
+		g.bld.CreateBr(endBlock)
+	}
+
+	g.bld.SetInsertPointAtEnd(thenBlock)
+	g.generateBlockStatement(st.Then)
+	fallThrough()
+
+	g.bld.SetInsertPointAtEnd(elseBlock)
+	if st.Else != nil {
+		g.generateStatement(st.Else)
+	}
+	fallThrough()
+
+	if !endBlock.IsNil() {
+		g.bld.SetInsertPointAtEnd(endBlock)
+	}
+}
+
+// Generates every kind of loop. Without a condition the loop only ends through break or return,
+// the post expression runs after each iteration and on continue.
+func (g *llvmGenerator) generateLoop(loop ast.Statement, name string, cond, post ast.Expression, body *ast.BlockStatement) {
+	condBlock := g.ctx.AddBasicBlock(g.currentFunction, name+".cond")
+	bodyBlock := g.ctx.AddBasicBlock(g.currentFunction, name+".body")
+	continueBlock := condBlock
+	if post != nil {
+		continueBlock = g.ctx.AddBasicBlock(g.currentFunction, name+".post")
+	}
+	endBlock := g.ctx.AddBasicBlock(g.currentFunction, name+".end")
+
+	g.loops[loop] = loopBlocks{continueBlock: continueBlock, breakBlock: endBlock}
+
+	g.bld.CreateBr(condBlock)
+	g.bld.SetInsertPointAtEnd(condBlock)
+	if cond != nil {
+		g.bld.CreateCondBr(g.generateExpression(cond), bodyBlock, endBlock)
+	} else {
+		g.bld.CreateBr(bodyBlock)
+	}
+
+	g.bld.SetInsertPointAtEnd(bodyBlock)
+	g.generateBlockStatement(body)
+	if !g.isTerminated() {
+		g.bld.CreateBr(continueBlock)
+	}
+
+	if post != nil {
+		g.bld.SetInsertPointAtEnd(continueBlock)
+		g.generateExpression(post)
+		g.bld.CreateBr(condBlock)
+	}
+
+	// Stays empty when nothing leaves the loop, code after it is then unreachable
+	g.bld.SetInsertPointAtEnd(endBlock)
+}
+
+func (g *llvmGenerator) generateReturnStatement(ret *ast.ReturnStatement) {
+	if ret.Value == nil {
+		g.bld.CreateRetVoid()
+		return
+	}
+
+	g.bld.CreateRet(g.generateExpression(ret.Value))
+}
+
+func (g *llvmGenerator) generateExpression(expr ast.Expression) llvm.Value {
+	// Untyped constant expressions were folded by sema, only their final value matters
+	if base := expr.ExprNode(); base.Const != nil {
+		return g.constantValue(base.Const, base.Type)
+	}
+
+	switch e := expr.(type) {
+	case *ast.Literal:
+		return g.generateLiteralExpr(e)
+	case *ast.Identifier:
+		return g.generateIdentifierExpr(e)
+	case *ast.Unary:
+		return g.generateUnaryExpr(e)
+	case *ast.Binary:
+		return g.generateBinaryExpr(e)
+	case *ast.Assignment:
+		return g.generateAssignmentExpr(e)
+	case *ast.Call:
+		return g.generateCallExpr(e)
+	case *ast.Cast:
+		return g.generateCastExpr(e)
+	case *ast.Indexed:
+		return g.bld.CreateLoad(g.llvmType(e.Type), g.elementAddress(e), "")
+	case *ast.Sliced:
+		return g.generateSlicedExpr(e)
+	case *ast.ArrayLiteral:
+		return g.generateArrayLiteral(e)
+	default:
+		panic(genPanic("unsupported expression"))
+	}
+}
+
+func (g *llvmGenerator) generateLiteralExpr(e *ast.Literal) llvm.Value {
+	t := g.llvmType(e.Type)
+
+	// LLVM aborts the process on constants of the wrong kind, the mismatch must be caught here
+	if !literalMatchesType(e.Value.Value, t) {
+		panic(genPanic("literal %s cannot be lowered to type %q", e.Value.String(), e.Type.String()))
+	}
+
+	switch v := e.Value.Value.(type) {
+	case int8:
+		return llvm.ConstInt(t, uint64(v), true)
+	case int16:
+		return llvm.ConstInt(t, uint64(v), true)
+	case int32:
+		return llvm.ConstInt(t, uint64(v), true)
+	case int64:
+		return llvm.ConstInt(t, uint64(v), true)
+	case uint8:
+		return llvm.ConstInt(t, uint64(v), false)
+	case uint16:
+		return llvm.ConstInt(t, uint64(v), false)
+	case uint32:
+		return llvm.ConstInt(t, uint64(v), false)
+	case uint64:
+		return llvm.ConstInt(t, v, false)
+	case float32:
+		return llvm.ConstFloat(t, float64(v))
+	case float64:
+		return llvm.ConstFloat(t, v)
+	case bool:
+		if v {
+			return llvm.ConstInt(t, 1, false)
+		}
+		return llvm.ConstInt(t, 0, false)
+	case string:
+		return g.bld.CreateGlobalStringPtr(v, ".str")
+	case nil:
+		return llvm.ConstPointerNull(t)
+	default:
+		panic(genPanic("unsupported literal %s", e.Value.String()))
+	}
+}
+
+// Reports whether the Go value of a literal has the kind of the LLVM type it is lowered to
+func literalMatchesType(v any, t llvm.Type) bool {
+	switch v.(type) {
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64, bool:
+		return t.TypeKind() == llvm.IntegerTypeKind
+	case float32, float64:
+		return t.TypeKind() == llvm.FloatTypeKind || t.TypeKind() == llvm.DoubleTypeKind
+	case nil:
+		return t.TypeKind() == llvm.PointerTypeKind
+	default:
+		// Strings are lowered to a global whatever the type, others are reported by the caller
+		return true
+	}
+}
+
+// Lowers an exact constant, already checked to fit in its type
+func (g *llvmGenerator) constantValue(c constant.Value, t types.Type) llvm.Value {
+	lt := g.llvmType(t)
+
+	switch {
+	case types.IsFloat(t):
+		f, _ := constant.Float64Val(constant.ToFloat(c))
+		return llvm.ConstFloat(lt, f)
+	case types.IsSigned(t):
+		i, _ := constant.Int64Val(c)
+		return llvm.ConstInt(lt, uint64(i), true)
+	default:
+		u, _ := constant.Uint64Val(c)
+		return llvm.ConstInt(lt, u, false)
+	}
+}
+
+func (g *llvmGenerator) generateIdentifierExpr(e *ast.Identifier) llvm.Value {
+	if slot, ok := g.locals[e.Decl]; ok {
+		return g.bld.CreateLoad(g.llvmType(e.Type), slot, e.Ident.Identifier)
+	}
+
+	// Predeclared constants are declared by the literal of their value
+	if lit, ok := e.Decl.(*ast.Literal); ok {
+		return g.generateLiteralExpr(lit)
+	}
+
+	fn := g.mod.NamedFunction(e.Ident.Identifier)
+	if fn.IsNil() {
+		panic(genPanic("unresolved identifier %q", e.Ident.Identifier))
+	}
+	return fn
+}
+
+func (g *llvmGenerator) generateUnaryExpr(e *ast.Unary) llvm.Value {
+	if e.Op.Kind == token.TokOpAmpersand {
+		return g.generatePlace(e.SubExpr)
+	}
+
+	v := g.generateExpression(e.SubExpr)
+
+	switch e.Op.Kind {
+	case token.TokOpPlus:
+		return v
+	case token.TokOpMinus:
+		if types.IsFloat(e.Type) {
+			return g.bld.CreateFNeg(v, "")
+		}
+		return g.bld.CreateNeg(v, "")
+	case token.TokOpBang, token.TokOpTilde:
+		return g.bld.CreateNot(v, "")
+	case token.TokOpStar:
+		return g.bld.CreateLoad(g.llvmType(e.Type), v, "")
+	default:
+		panic(genPanic("unsupported unary operator %s", e.Op.String()))
+	}
+}
+
+// Ordered comparisons are false when a NaN is involved, which makes '!=' the only one that holds
+var floatPredicates = map[token.TokenType]llvm.FloatPredicate{
+	token.TokOpEq:           llvm.FloatOEQ,
+	token.TokOpNotEq:        llvm.FloatUNE,
+	token.TokOpLessThan:     llvm.FloatOLT,
+	token.TokOpGreaterThan:  llvm.FloatOGT,
+	token.TokOpLessEqual:    llvm.FloatOLE,
+	token.TokOpGreaterEqual: llvm.FloatOGE,
+}
+
+var signedPredicates = map[token.TokenType]llvm.IntPredicate{
+	token.TokOpEq:           llvm.IntEQ,
+	token.TokOpNotEq:        llvm.IntNE,
+	token.TokOpLessThan:     llvm.IntSLT,
+	token.TokOpGreaterThan:  llvm.IntSGT,
+	token.TokOpLessEqual:    llvm.IntSLE,
+	token.TokOpGreaterEqual: llvm.IntSGE,
+}
+
+// Also used for every non-numeric comparable type, which only supports equality
+var unsignedPredicates = map[token.TokenType]llvm.IntPredicate{
+	token.TokOpEq:           llvm.IntEQ,
+	token.TokOpNotEq:        llvm.IntNE,
+	token.TokOpLessThan:     llvm.IntULT,
+	token.TokOpGreaterThan:  llvm.IntUGT,
+	token.TokOpLessEqual:    llvm.IntULE,
+	token.TokOpGreaterEqual: llvm.IntUGE,
+}
+
+func (g *llvmGenerator) generateBinaryExpr(e *ast.Binary) llvm.Value {
+	if e.Op.Kind == token.TokOpLogicalAnd || e.Op.Kind == token.TokOpLogicalOr {
+		return g.generateLogicalExpr(e)
+	}
+
+	l := g.generateExpression(e.Left)
+	r := g.generateExpression(e.Right)
+
+	return g.binaryOp(e.Op.Kind, l, r, e.Left.ExprNode().Type)
+}
+
+// Applies a non short-circuiting binary operator to operands of the given type
+func (g *llvmGenerator) binaryOp(op token.TokenType, l, r llvm.Value, operandType types.Type) llvm.Value {
+	float := types.IsFloat(operandType)
+	signed := types.IsSigned(operandType)
+
+	switch op {
+	case token.TokOpPlus:
+		if float {
+			return g.bld.CreateFAdd(l, r, "")
+		}
+		return g.bld.CreateAdd(l, r, "")
+	case token.TokOpMinus:
+		if float {
+			return g.bld.CreateFSub(l, r, "")
+		}
+		return g.bld.CreateSub(l, r, "")
+	case token.TokOpStar:
+		if float {
+			return g.bld.CreateFMul(l, r, "")
+		}
+		return g.bld.CreateMul(l, r, "")
+	case token.TokOpSlash:
+		switch {
+		case float:
+			return g.bld.CreateFDiv(l, r, "")
+		case signed:
+			return g.bld.CreateSDiv(l, r, "")
+		default:
+			return g.bld.CreateUDiv(l, r, "")
+		}
+	case token.TokOpMod:
+		switch {
+		case float:
+			return g.bld.CreateFRem(l, r, "")
+		case signed:
+			return g.bld.CreateSRem(l, r, "")
+		default:
+			return g.bld.CreateURem(l, r, "")
+		}
+	case token.TokOpAmpersand:
+		return g.bld.CreateAnd(l, r, "")
+	case token.TokOpPipe:
+		return g.bld.CreateOr(l, r, "")
+	case token.TokOpCaret:
+		return g.bld.CreateXor(l, r, "")
+	case token.TokOpShiftLeft, token.TokOpShiftRight:
+		// The count is unsigned and may have any width, LLVM wants both operands of the same type
+		r = g.resizeUnsigned(r, l.Type())
+		switch {
+		case op == token.TokOpShiftLeft:
+			return g.bld.CreateShl(l, r, "")
+		case signed:
+			return g.bld.CreateAShr(l, r, "")
+		default:
+			return g.bld.CreateLShr(l, r, "")
+		}
+	case token.TokOpEq, token.TokOpNotEq, token.TokOpLessThan, token.TokOpGreaterThan, token.TokOpLessEqual, token.TokOpGreaterEqual:
+		if float {
+			return g.bld.CreateFCmp(floatPredicates[op], l, r, "")
+		}
+		if signed {
+			return g.bld.CreateICmp(signedPredicates[op], l, r, "")
+		}
+		return g.bld.CreateICmp(unsignedPredicates[op], l, r, "")
+	default:
+		panic(genPanic("unsupported binary operator %s", op.String()))
+	}
+}
+
+func (g *llvmGenerator) generateCallExpr(e *ast.Call) llvm.Value {
+	if e.Builtin != "" {
+		return g.generateBuiltinCall(e)
+	}
+
+	ft := e.Callee.ExprNode().Type.(*types.Function)
+	fn := g.generateExpression(e.Callee)
+
+	args := make([]llvm.Value, 0, len(e.Args))
+	for _, arg := range e.Args {
+		args = append(args, g.generateExpression(arg))
+	}
+
+	// Values of type void cannot be named
+	return g.bld.CreateCall(g.functionType(ft), fn, args, "")
+}
+
+func (g *llvmGenerator) generateCastExpr(e *ast.Cast) llvm.Value {
+	v := g.generateExpression(e.Value)
+	from := e.Value.ExprNode().Type
+	to := g.llvmType(e.Type)
+
+	switch types.ClassifyCast(from, e.Type) {
+	case types.CastNone:
+		return v
+	case types.CastIntResize:
+		fromBits, toBits := v.Type().IntTypeWidth(), to.IntTypeWidth()
+		switch {
+		case fromBits > toBits:
+			return g.bld.CreateTrunc(v, to, "")
+		case fromBits == toBits:
+			return v
+		case types.IsSigned(from):
+			return g.bld.CreateSExt(v, to, "")
+		default:
+			return g.bld.CreateZExt(v, to, "")
+		}
+	case types.CastIntToFloat:
+		if types.IsSigned(from) {
+			return g.bld.CreateSIToFP(v, to, "")
+		}
+		return g.bld.CreateUIToFP(v, to, "")
+	case types.CastFloatToInt:
+		if types.IsSigned(e.Type) {
+			return g.bld.CreateFPToSI(v, to, "")
+		}
+		return g.bld.CreateFPToUI(v, to, "")
+	case types.CastFloatResize:
+		if types.Underlying(from) == types.F32 {
+			return g.bld.CreateFPExt(v, to, "")
+		}
+		return g.bld.CreateFPTrunc(v, to, "")
+	case types.CastIntToPtr:
+		return g.bld.CreateIntToPtr(v, to, "")
+	case types.CastPtrToInt:
+		return g.bld.CreatePtrToInt(v, to, "")
+	case types.CastPtrToPtr:
+		return g.bld.CreatePointerCast(v, to, "")
+	default:
+		panic(genPanic("invalid cast from %q to %q", from.String(), e.Type.String()))
+	}
+}
+
+// Returns the address of an assignable expression
+func (g *llvmGenerator) generatePlace(expr ast.Expression) llvm.Value {
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		if slot, ok := g.locals[e.Decl]; ok {
+			return slot
+		}
+		panic(genPanic("%q is not a local variable", e.Ident.Identifier))
+	case *ast.Unary:
+		if e.Op.Kind == token.TokOpStar {
+			// The pointer is the address of the value it points to
+			return g.generateExpression(e.SubExpr)
+		}
+		panic(genPanic("unsupported assignment target"))
+	case *ast.Indexed:
+		return g.elementAddress(e)
+	default:
+		panic(genPanic("unsupported assignment target"))
+	}
+}
+
+// Stores the value into its target and evaluates to the stored value
+func (g *llvmGenerator) generateAssignmentExpr(e *ast.Assignment) llvm.Value {
+	place := g.generatePlace(e.Target)
+	v := g.generateExpression(e.Value)
+
+	if op, ok := e.Op.Kind.CompoundOperator(); ok {
+		tt := e.Target.ExprNode().Type
+		old := g.bld.CreateLoad(g.llvmType(tt), place, "")
+		v = g.binaryOp(op, old, v, tt)
+	}
+
+	g.bld.CreateStore(v, place)
+	return v
+}
+
+// Generates '&&' and '||'. The right operand is only evaluated when the left one does not decide the result.
+func (g *llvmGenerator) generateLogicalExpr(e *ast.Binary) llvm.Value {
+	isAnd := e.Op.Kind == token.TokOpLogicalAnd
+
+	l := g.generateExpression(e.Left)
+	lhsEnd := g.bld.GetInsertBlock()
+
+	rhsBlock := g.ctx.AddBasicBlock(g.currentFunction, "logic.rhs")
+	endBlock := g.ctx.AddBasicBlock(g.currentFunction, "logic.end")
+
+	if isAnd {
+		g.bld.CreateCondBr(l, rhsBlock, endBlock)
+	} else {
+		g.bld.CreateCondBr(l, endBlock, rhsBlock)
+	}
+
+	g.bld.SetInsertPointAtEnd(rhsBlock)
+	r := g.generateExpression(e.Right)
+	rhsEnd := g.bld.GetInsertBlock()
+	g.bld.CreateBr(endBlock)
+
+	// Coming straight from the left operand means it was false for '&&' and true for '||'
+	short := llvm.ConstInt(g.ctx.Int1Type(), 0, false)
+	if !isAnd {
+		short = llvm.ConstInt(g.ctx.Int1Type(), 1, false)
+	}
+
+	g.bld.SetInsertPointAtEnd(endBlock)
+	phi := g.bld.CreatePHI(g.ctx.Int1Type(), "")
+	phi.AddIncoming([]llvm.Value{short, r}, []llvm.BasicBlock{lhsEnd, rhsEnd})
+	return phi
 }
@@ -2,6 +2,7 @@ package llvmback
 
 import (
 	"fmt"
+	"fracta/internal/ast"
 
 	"tinygo.org/x/go-llvm"
 )
@@ -10,6 +11,16 @@ type llvmGenerator struct {
 	ctx llvm.Context
 	mod llvm.Module
 	bld llvm.Builder
+
+	currentFunction llvm.Value
+	locals          map[ast.ASTNode]llvm.Value // Stack slot of every local of the current function, by declaration
+	loops           map[ast.Statement]loopBlocks
+}
+
+// Where break and continue jump to for a given loop
+type loopBlocks struct {
+	continueBlock llvm.BasicBlock
+	breakBlock    llvm.BasicBlock
 }
 
 type generationPanic struct {
@@ -0,0 +1,81 @@
+package llvmback
+
+import (
+	"fracta/internal/types"
+
+	"tinygo.org/x/go-llvm"
+)
+
+func (g *llvmGenerator) llvmType(t types.Type) llvm.Type {
+	switch t := types.Underlying(t).(type) {
+	case *types.Basic:
+		return g.basicType(t)
+	case *types.Function:
+		return llvm.PointerType(g.functionType(t), 0)
+	case *types.Pointer:
+		return llvm.PointerType(g.llvmType(t.Elem()), 0)
+	case *types.Slice:
+		return g.sliceType(t)
+	case *types.Array:
+		return llvm.ArrayType(g.llvmType(t.Elem()), int(t.Len()))
+	case *types.Struct:
+		fields := make([]llvm.Type, 0, len(t.Fields()))
+		for _, f := range t.Fields() {
+			fields = append(fields, g.llvmType(f.Type))
+		}
+		return g.ctx.StructType(fields, false)
+	default:
+		panic(genPanic("cannot lower type %q", t.String()))
+	}
+}
+
+func (g *llvmGenerator) basicType(t *types.Basic) llvm.Type {
+	switch t.Kind() {
+	case types.KindVoid:
+		return g.ctx.VoidType()
+	case types.KindI8, types.KindU8:
+		return g.ctx.Int8Type()
+	case types.KindI16, types.KindU16:
+		return g.ctx.Int16Type()
+	case types.KindI32, types.KindU32:
+		return g.ctx.Int32Type()
+	case types.KindI64, types.KindU64:
+		return g.ctx.Int64Type()
+	case types.KindF32:
+		return g.ctx.FloatType()
+	case types.KindF64:
+		return g.ctx.DoubleType()
+	case types.KindBool:
+		return g.ctx.Int1Type()
+	case types.KindChar:
+		return g.ctx.Int32Type()
+	case types.KindStr, types.KindPtr, types.KindUntypedNull:
+		return llvm.PointerType(g.ctx.Int8Type(), 0)
+	default:
+		panic(genPanic("cannot lower type %q", t.String()))
+	}
+}
+
+func (g *llvmGenerator) functionType(t *types.Function) llvm.Type {
+	params := make([]llvm.Type, 0, len(t.Params()))
+
+	for _, v := range t.Params() {
+		params = append(params, g.llvmType(v))
+	}
+
+	return llvm.FunctionType(g.llvmType(t.Result()), params, false)
+}
+
+// Zero-extends or truncates an unsigned integer value to the given integer type
+func (g *llvmGenerator) resizeUnsigned(v llvm.Value, t llvm.Type) llvm.Value {
+	from, to := v.Type().IntTypeWidth(), t.IntTypeWidth()
+
+	switch {
+	case from < to:
+		return g.bld.CreateZExt(v, t, "")
+	case from > to:
+		return g.bld.CreateTrunc(v, t, "")
+	default:
+		return v
+	}
+}
@@ -0,0 +1,584 @@
+package diag
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Stable identifier of a kind of diagnostic. Codes never change meaning once published.
+type Code string
+
+// Lexical errors
+const (
+	ErrUnterminatedComment    Code = "E0001"
+	ErrInvalidNumber          Code = "E0002"
+	ErrUnterminatedString     Code = "E0003"
+	ErrInvalidEscape          Code = "E0004"
+	ErrUnterminatedChar       Code = "E0005"
+	ErrInvalidChar            Code = "E0006"
+	ErrUnexpectedCharacter    Code = "E0007"
+	ErrInvalidCodePoint       Code = "E0008"
+	ErrInvalidMultilineString Code = "E0009"
+	ErrLiteralOverflow        Code = "E0010"
+)
+
+// Syntax errors
+const (
+	ErrExpectedToken    Code = "E0050"
+	ErrInvalidExprToken Code = "E0051"
+	ErrInvalidTypeExpr  Code = "E0052"
+)
+
+// Semantic errors
+const (
+	ErrInvalidTopLevel          Code = "E0100"
+	ErrReturnTypeMismatch       Code = "E0101"
+	ErrRedefinition             Code = "E0102"
+	ErrUndefinedSymbol          Code = "E0103"
+	ErrMismatchedOperands       Code = "E0104"
+	ErrInvalidOperandType       Code = "E0105"
+	ErrInvalidOperator          Code = "E0106"
+	ErrUnsupported              Code = "E0107"
+	ErrReturnValueInVoid        Code = "E0108"
+	ErrMissingReturnValue       Code = "E0109"
+	ErrInvalidFunctionBody      Code = "E0110"
+	ErrTypeMismatch             Code = "E0111"
+	ErrMissingInitializer       Code = "E0112"
+	ErrCannotInferType          Code = "E0113"
+	ErrAssignToImmutable        Code = "E0114"
+	ErrAssignToFunction         Code = "E0115"
+	ErrNotAssignable            Code = "E0116"
+	ErrNonBoolCondition         Code = "E0117"
+	ErrJumpOutsideLoop          Code = "E0118"
+	ErrUndefinedLabel           Code = "E0119"
+	ErrNotCallable              Code = "E0120"
+	ErrArgumentCount            Code = "E0121"
+	ErrConstantNotRepresentable Code = "E0122"
+	ErrDivisionByZero           Code = "E0123"
+	ErrInvalidCast              Code = "E0124"
+	ErrNotAddressable           Code = "E0125"
+	ErrInvalidDereference       Code = "E0126"
+	ErrNotIndexable             Code = "E0127"
+	ErrIndexCount               Code = "E0128"
+	ErrIndexOutOfBounds         Code = "E0129"
+	ErrInvalidArrayLength       Code = "E0130"
+	ErrBuiltinValue             Code = "E0131"
+	ErrMissingReturn            Code = "E0132"
+)
+
+type codeInfo struct {
+	title       string
+	explanation string
+}
+
+var codeTable = map[Code]codeInfo{
+	ErrUnterminatedComment: {
+		title: "unterminated block comment",
+		explanation: `A block comment was opened with '/*' but the end of the file was reached
+before the matching '*/'.
+
+    /* this comment never ends
+    func main() i32 { return 0i; }
+
+Close the comment with '*/'.`,
+	},
+	ErrInvalidNumber: {
+		title: "invalid number literal",
+		explanation: `A number literal is malformed. Integers may be written in decimal, or with
+the 0b, 0o and 0x prefixes. Floating point literals are decimal or
+hexadecimal, and need at least one digit after the decimal point.
+Hexadecimal floats always have a binary exponent, written with p, as in
+0x1.8p3 (1.5 * 2^3).
+
+    1.      // error: missing digit after '.'
+    0b102   // error: '2' is not a binary digit
+    12q     // error: unknown suffix
+    0x1.8   // error: missing 'p' exponent
+
+Valid suffixes are b, s, i, l (signed), ub, us, ui, ul (unsigned), and
+f, d (floating point).
+
+Digits may be grouped with single underscores, as in 1_000_000 or
+0xFFFF_0000. An underscore must sit between two digits, or right after a
+base prefix.
+
+    1__000  // error: doubled separator
+    1_.5    // error: separator next to '.'`,
+	},
+	ErrUnterminatedString: {
+		title: "unterminated string literal",
+		explanation: `A string literal was opened with '"' but the end of the line was reached
+before the closing quote.
+
+    func main() { "hello; }
+
+Add the missing '"'. Strings that need to span several lines can be written
+as raw strings between backticks, or as multi-line strings between '"""'.
+Those two are only unterminated when the end of the file is reached.`,
+	},
+	ErrInvalidEscape: {
+		title: "invalid escape sequence",
+		explanation: `A string or character literal contains a backslash followed by a
+character that does not form a known escape sequence.
+
+    "C:\path"   // error: '\p' is not an escape sequence
+
+The known escapes are \a \b \f \n \r \t \v \\ \' \", the byte escapes \xNN
+(two hex digits) and \NNN (up to three octal digits, at most \377), and
+\u{N} with 1 to 6 hex digits for any code point. Byte escapes are limited to
+ASCII in character literals.
+
+Escape the backslash itself with '\\', or use a raw string: ` + "`C:\\path`" + `.`,
+	},
+	ErrUnterminatedChar: {
+		title: "unterminated character literal",
+		explanation: `A character literal was opened with a single quote but the end of the
+file was reached before the closing quote.
+
+    'a
+
+Add the missing '\''.`,
+	},
+	ErrInvalidChar: {
+		title: "invalid character literal",
+		explanation: `A character literal must contain exactly one character, or a single
+escape sequence.
+
+    ''      // error: empty
+    'ab'    // error: two characters
+
+Use a string literal for more than one character.`,
+	},
+	ErrUnexpectedCharacter: {
+		title: "unexpected character",
+		explanation: `The source contains characters that cannot start any token, such as '@'
+or '$' outside of a string or character literal.
+
+    func main() i32 {
+        return 1i @ 2i;   // error: '@' is not an operator
+    }
+
+Lexing resumes right after the offending characters, so later errors in
+the same file are still reported.`,
+	},
+	ErrInvalidCodePoint: {
+		title: "invalid code point",
+		explanation: `A \u{...} escape names a value that is not a Unicode scalar value. Code
+points go up to U+10FFFF, and the surrogate range U+D800 to U+DFFF is
+reserved for UTF-16 and cannot appear on its own.
+
+    "\u{110000}"   // error: out of range
+    '\u{D83D}'     // error: surrogate
+
+Write the character itself, or its actual code point.`,
+	},
+	ErrInvalidMultilineString: {
+		title: "malformed multi-line string",
+		explanation: `A '"""' string must have its content start on the line after the
+opening delimiter, and the closing delimiter must be alone on its line.
+The indentation of the closing delimiter is removed from every line, so no
+content line may be indented less than it.
+
+    return """
+        {"key": 1}
+          nested
+        """;          // same as "{\"key\": 1}\n  nested"
+
+Blank lines are allowed at any indentation.`,
+	},
+	ErrLiteralOverflow: {
+		title: "numeric literal out of range",
+		explanation: `The value of a number literal does not fit in the type given by its
+suffix.
+
+    300b      // error: i8 goes from -128 to 127
+    70000us   // error: u16 goes from 0 to 65535
+    1e39f     // error: too large for f32
+
+Use a suffix for a wider type, such as s (i16) or ui (u32).`,
+	},
+	ErrExpectedToken: {
+		title: "expected token",
+		explanation: `The parser expected a specific token, such as ';' or ')', but found
+something else.
+
+    func main() i32 {
+        return 0i      // error: expected ';'
+    }
+
+Add the missing token.`,
+	},
+	ErrInvalidExprToken: {
+		title: "invalid token in expression",
+		explanation: `A token that cannot start an expression appeared where an expression was
+expected.
+
+    return ;+ 1i;   // error: ';' cannot start an expression`,
+	},
+	ErrInvalidTypeExpr: {
+		title: "invalid type expression",
+		explanation: `A type was expected, but the tokens found do not form one. Types are
+either builtin (i8 to i64, u8 to u64, f32, f64, bool, ptr) or named.
+
+    func f(a 32) {}   // error: '32' is not a type`,
+	},
+	ErrInvalidTopLevel: {
+		title: "invalid top-level statement",
+		explanation: `Only declarations are allowed at the top level of a file. Statements
+such as returns or expressions must be placed inside a function body.
+
+    return 1i;   // error: not inside a function
+
+    func main() i32 {
+        return 1i;  // ok
+    }`,
+	},
+	ErrReturnTypeMismatch: {
+		title: "return type mismatch",
+		explanation: `The value of a return statement does not have the type the function
+was declared to return. No implicit conversions are performed.
+
+    func main() i32 {
+        return 2.0;   // error: f64 returned, i32 expected
+    }
+
+Return a value of the declared type, for example 2i.`,
+	},
+	ErrRedefinition: {
+		title: "symbol redefinition",
+		explanation: `A name was declared more than once in the same scope, or shadows a name
+from an enclosing scope.
+
+    func main() i32 { return 0i; }
+    func main() i32 { return 1i; }   // error: main already defined
+
+Rename one of the declarations.`,
+	},
+	ErrUndefinedSymbol: {
+		title: "undefined symbol",
+		explanation: `A name was used, but no declaration for it is visible from this scope.
+
+    func main() i32 {
+        return foo;   // error: foo is not defined
+    }
+
+Check the spelling, or declare the symbol.`,
+	},
+	ErrMismatchedOperands: {
+		title: "mismatched operand types",
+		explanation: `Both operands of a binary operator must have the same type. No implicit
+conversions are performed.
+
+    1i + 2.0   // error: i32 and f64
+
+Make both operands the same type.`,
+	},
+	ErrInvalidOperandType: {
+		title: "invalid operand type",
+		explanation: `An operator was applied to a value of a type it does not support, for
+example arithmetic on a non-numeric value.
+
+    -main   // error: main is a function`,
+	},
+	ErrInvalidOperator: {
+		title: "invalid operator",
+		explanation: `The operator cannot be used in this position, for example a binary-only
+operator used as a prefix.
+
+    return *1i;   // error: '*' is not a valid unary operator here`,
+	},
+	ErrUnsupported: {
+		title:       "unsupported construct",
+		explanation: `The construct is valid syntax, but the compiler does not support it yet.`,
+	},
+	ErrReturnValueInVoid: {
+		title: "return with value in a void function",
+		explanation: `A function declared without a return type returned a value.
+
+    func f() {
+        return 1i;   // error
+    }
+
+Either remove the value, or declare a return type.`,
+	},
+	ErrMissingReturnValue: {
+		title: "missing return value",
+		explanation: `A function declared with a return type has a return statement without a
+value.
+
+    func f() i32 {
+        return;   // error: an i32 must be returned
+    }`,
+	},
+	ErrInvalidFunctionBody: {
+		title:       "invalid function body",
+		explanation: `A function body must be a block statement enclosed in braces.`,
+	},
+	ErrTypeMismatch: {
+		title: "mismatched types",
+		explanation: `A value is stored somewhere that expects a value of a different type.
+There are no implicit conversions, except for null which can be used as
+any pointer.
+
+    var x i32 = 1l;    // error: 1l is an i64
+    var p ptr = null;  // fine
+
+Use a literal of the right type, or change the declared type.`,
+	},
+	ErrMissingInitializer: {
+		title: "missing initializer",
+		explanation: `A local declaration lacks the information it needs. A 'let' binding can
+never be assigned later, so it must be initialized. A 'var' without an
+initializer starts as zero, but then needs an explicit type.
+
+    let a i32;     // error: no initializer
+    var b;         // error: no type and no initializer
+    var c i32;     // fine, c is 0`,
+	},
+	ErrCannotInferType: {
+		title: "cannot infer type",
+		explanation: `The type of a variable is inferred from its initializer, but the
+initializer does not have a type of its own. This happens with null,
+which fits any pointer type.
+
+    var p = null;      // error
+    var q ptr = null;  // fine`,
+	},
+	ErrAssignToImmutable: {
+		title: "assignment to an immutable binding",
+		explanation: `A 'let' binding is assigned after its declaration. Bindings declared
+with 'let' keep their initial value for their whole lifetime.
+
+    let total = 0i;
+    total += 1i;     // error
+
+Declare it with 'var' if it needs to change.`,
+	},
+	ErrAssignToFunction: {
+		title: "assignment to a function",
+		explanation: `The left side of an assignment names a function. Functions are not
+variables and cannot be replaced at run time.
+
+    func f() {}
+    func main() { f = g; }   // error`,
+	},
+	ErrNotAssignable: {
+		title: "invalid assignment target",
+		explanation: `The left side of an assignment does not denote a storage location. Only
+mutable variables, dereferenced pointers and indexed elements can be
+assigned to.
+
+    a + b = 1i;   // error: a + b is a value, not a place
+    (a) = 1i;     // fine`,
+	},
+	ErrNonBoolCondition: {
+		title: "non-boolean condition",
+		explanation: `The condition of an 'if' is not of type 'bool'. Fracta has no implicit
+truthiness, numbers and pointers must be compared explicitly.
+
+    if count { ... }          // error
+    if count != 0i { ... }    // fine
+
+The same applies to the conditions of 'while' and 'for' loops.`,
+	},
+	ErrJumpOutsideLoop: {
+		title: "break or continue outside of a loop",
+		explanation: `A 'break' or 'continue' appears where no loop encloses it. Both only
+make sense inside the body of a 'while', 'for' or 'loop'.
+
+    func f() { break; }   // error`,
+	},
+	ErrUndefinedLabel: {
+		title: "undefined label",
+		explanation: `A 'break' or 'continue' names a label that no enclosing loop has.
+Labels are written before the loop keyword and are only visible within
+the body of their loop.
+
+    outer: loop {
+        loop { break outer; }   // fine
+    }
+    break outer;                // error, outside of the labeled loop`,
+	},
+	ErrNotCallable: {
+		title: "call of a non-function value",
+		explanation: `The called expression is not a function. Only functions can be called.
+
+    var n = 1i;
+    n(2i);   // error`,
+	},
+	ErrArgumentCount: {
+		title: "wrong number of arguments",
+		explanation: `A function is called with more or fewer arguments than it has
+parameters. Every parameter must be given exactly one argument.
+
+    func add(a i32, b i32) i32 { return a + b; }
+    add(1i);          // error
+    add(1i, 2i);      // fine`,
+	},
+	ErrConstantNotRepresentable: {
+		title: "constant not representable",
+		explanation: `A number literal without a suffix is an untyped constant, which takes
+the type its context expects. The value of the constant must fit in that
+type, and be a whole number for integer types.
+
+    let a u8 = 256;       // error: the range of u8 is 0 to 255
+    let b i32 = 2.5;      // error: not an integer
+    let c i32 = 2.0;      // fine
+    let d = 1 << 70;      // error: defaults to i64, which is too small
+
+Constant expressions are computed exactly before the check, so only the
+final value needs to fit.`,
+	},
+	ErrDivisionByZero: {
+		title: "division by zero",
+		explanation: `A constant expression divides by zero, or takes a remainder by zero.
+Constant expressions are evaluated while compiling, so this is known
+to fail.
+
+    let a = 1 / 0;   // error`,
+	},
+	ErrInvalidCast: {
+		title: "invalid cast",
+		explanation: `An 'as' cast converts between two types that have no conversion.
+Casts are allowed between any numeric types, from bool and char to
+integers, from integers to char, and between integers and pointers.
+Typed pointers convert to and from ptr, which is how ptr is turned into a
+typed pointer. Reinterpreting a *T as a *U must go through ptr.
+
+    let a = 3.9 as i32;      // fine, truncates to 3
+    let b = 300i as u8;      // fine, wraps to 44
+    let c = main as f64;     // error: a function is not a number
+    let d = 1i as bool;      // error: write 1i != 0i instead
+    let e = raw as *u8;      // fine, raw is a ptr
+    let f = e as *u32;       // error: write e as ptr as *u32`,
+	},
+	ErrNotAddressable: {
+		title: "invalid address-of operand",
+		explanation: `The operand of '&' does not denote a mutable storage location. A pointer
+can be used to write the value it points to, so only mutable variables,
+dereferenced pointers and indexed elements have an address. Slicing an
+array takes its address, and has the same requirement.
+
+    let n = 1i;
+    var m = 1i;
+    let p = &n;        // error: n is a let binding
+    let q = &(m + 1i); // error: m + 1i is a value, not a place
+    let r = &m;        // fine`,
+	},
+	ErrInvalidDereference: {
+		title: "dereference of a non-pointer",
+		explanation: `The operand of the '*' prefix operator is not a typed pointer. Only a
+pointer such as *i32 knows the type of the value it points to, ptr has
+to be cast to one first.
+
+    func f(raw ptr, n i32) {
+        let a = *n;             // error: n is not a pointer
+        let b = *raw;           // error: ptr is opaque
+        let c = *(raw as *i32); // fine
+    }`,
+	},
+	ErrNotIndexable: {
+		title: "indexing a value that is not an array or slice",
+		explanation: `An index or slice expression is applied to a value that has no elements.
+Only arrays and slices can be indexed and sliced.
+
+    let n = 3i;
+    let a = n[0];           // error: n is an i32
+    let b = [1i, 2i][0];    // fine`,
+	},
+	ErrIndexCount: {
+		title: "wrong number of indices",
+		explanation: `An index expression has no index or more than one. Arrays have a single
+dimension, an array of arrays is indexed one level at a time.
+
+    var grid [3][4]i32;
+    grid[1, 2] = 5i;    // error
+    grid[1][2] = 5i;    // fine`,
+	},
+	ErrIndexOutOfBounds: {
+		title: "constant index out of bounds",
+		explanation: `A constant index or slice bound is negative or past the end of an array.
+Indices of an array of length N range from 0 to N - 1, and slice bounds
+from 0 to N with the low bound not above the high one.
+
+    var a [3]i32;
+    a[3] = 1i;          // error: the last index is 2
+    let s = a[2:1];     // error: the bounds are reversed
+    let t = a[1:3];     // fine
+
+Indices that are not constant are checked when the program runs, and
+stop it when they are out of bounds.`,
+	},
+	ErrInvalidArrayLength: {
+		title: "invalid array length",
+		explanation: `The length of an array type is not a non-negative integer constant. The
+length is part of the type, it must be known when compiling.
+
+    var a [4]u8;         // fine
+    var b [2 * 8]u8;     // fine
+    var c [-1]u8;        // error
+    var d [n]u8;         // error: n is a variable
+
+Use a slice for a run of elements whose length is only known at run time.`,
+	},
+	ErrBuiltinValue: {
+		title: "builtin function used as a value",
+		explanation: `A builtin function like len is used without calling it. Builtins are
+expanded by the compiler at each call and have no address.
+
+    let f = len;        // error
+    let n = len(a);     // fine`,
+	},
+	ErrMissingReturn: {
+		title: "missing return",
+		explanation: `Control can reach the end of a function that returns a value. Every path
+through the body must end with a return statement.
+
+    func sign(x i32) i32 {
+        if x < 0i { return -1i; }
+        if x > 0i { return 1i; }
+    }   // error: a zero x reaches the end
+
+    func abs(x i32) i32 {
+        if x < 0i { return -x; } else { return x; }
+    }   // fine
+
+A loop without a break never reaches the end, and needs no return after it.`,
+	},
+}
+
+// Returns the default severity for diagnostics with this code
+func (c Code) Severity() Severity {
+	if strings.HasPrefix(string(c), "W") {
+		return SeverityWarning
+	}
+	return SeverityError
+}
+
+// Returns a short description of the code
+func (c Code) Title() string {
+	return codeTable[c].title
+}
+
+// Returns the long description of a diagnostic code
+func Explain(code string) (string, error) {
+	c := Code(strings.ToUpper(code))
+
+	info, ok := codeTable[c]
+	if !ok {
+		return "", fmt.Errorf("unknown diagnostic code %q", code)
+	}
+
+	return fmt.Sprintf("%s: %s\n\n%s\n", c, info.title, info.explanation), nil
+}
+
+// Returns every known code, sorted
+func AllCodes() []Code {
+	out := make([]Code, 0, len(codeTable))
+	for k := range codeTable {
+		out = append(out, k)
+	}
+	slices.Sort(out)
+	return out
+}
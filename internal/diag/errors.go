@@ -2,17 +2,83 @@ package diag
 
 import (
 	"fmt"
+	"fracta/internal/token"
+	"os"
 	"strings"
 )
 
+// Secondary source location attached to a diagnostic
+type Label struct {
+	Span    token.Span
+	Message string
+}
+
+// Note or help message attached to a diagnostic
+type Note struct {
+	Severity Severity
+	Message  string
+}
+
+// Single text replacement of a suggested fix, an empty span inserts text
+type Edit struct {
+	Span    token.Span
+	NewText string
+}
+
+// Machine-applicable suggestion on how to fix a diagnostic
+type Fix struct {
+	Message string
+	Edits   []Edit
+}
+
 type ErrorContainer struct {
+	Severity Severity
+	Code     Code
 	Message  string
-	Filaname string
-	Line     int
+	Span     token.Span
+	Labels   []Label
+	Notes    []Note
+	Fixes    []Fix
 }
 
 func (e *ErrorContainer) Error() string {
-	return fmt.Sprintf("(%s:%d) %s", e.Filaname, e.Line, e.Message)
+	return fmt.Sprintf("(%s) %s[%s]: %s", e.Span.String(), e.Severity.String(), e.Code, e.Message)
+}
+
+// Attaches a secondary label to the diagnostic
+func (e *ErrorContainer) WithLabel(span token.Span, f string, v ...any) *ErrorContainer {
+	e.Labels = append(e.Labels, Label{
+		Span:    span,
+		Message: fmt.Sprintf(f, v...),
+	})
+	return e
+}
+
+// Attaches a free-standing note to the diagnostic
+func (e *ErrorContainer) WithNote(f string, v ...any) *ErrorContainer {
+	e.Notes = append(e.Notes, Note{
+		Severity: SeverityNote,
+		Message:  fmt.Sprintf(f, v...),
+	})
+	return e
+}
+
+// Attaches a suggestion on how to fix the issue
+func (e *ErrorContainer) WithHelp(f string, v ...any) *ErrorContainer {
+	e.Notes = append(e.Notes, Note{
+		Severity: SeverityHelp,
+		Message:  fmt.Sprintf(f, v...),
+	})
+	return e
+}
+
+// Attaches a suggested fix replacing the text of span with newText
+func (e *ErrorContainer) WithFix(span token.Span, newText string, f string, v ...any) *ErrorContainer {
+	e.Fixes = append(e.Fixes, Fix{
+		Message: fmt.Sprintf(f, v...),
+		Edits:   []Edit{{Span: span, NewText: newText}},
+	})
+	return e
 }
 
 type ErrorList []*ErrorContainer
@@ -20,22 +86,36 @@ type ErrorList []*ErrorContainer
 func (el ErrorList) Error() string {
 	sb := strings.Builder{}
 
-	for _, v := range el {
+	for i, v := range el {
+		if i != 0 {
+			sb.WriteByte('\n')
+		}
 		sb.WriteString(v.Error())
 	}
 	return sb.String()
 }
 
-func CreateError(msg, file string, line int) *ErrorContainer {
+// Reports whether any diagnostic of the list is an error
+func (el ErrorList) HasErrors() bool {
+	for _, v := range el {
+		if v.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Creates a diagnostic, its severity is the default one of the code
+func CreateError(code Code, msg string, span token.Span) *ErrorContainer {
 	return &ErrorContainer{
+		Severity: code.Severity(),
+		Code:     code,
 		Message:  msg,
-		Filaname: file,
-		Line:     line,
+		Span:     span,
 	}
 }
 
-func DiagnoseErrors(list ErrorList) {
-	for _, v := range list {
-		fmt.Println(v.Error())
-	}
+// Prints every diagnostic of the list to standard error
+func DiagnoseErrors(list ErrorList, mode ColorMode) {
+	_ = WriteDiagnostics(os.Stderr, list, FormatText, mode)
 }
@@ -0,0 +1,45 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+)
+
+// Output format for diagnostics
+type Format int
+
+const (
+	FormatText  Format = iota // Human-readable, with source excerpts
+	FormatJSON                // JSON document, see WriteJSON
+	FormatSARIF               // SARIF 2.1.0 log, see WriteSARIF
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "sarif":
+		return FormatSARIF, nil
+	default:
+		return FormatText, fmt.Errorf("invalid diagnostics format %q, expected text, json or sarif", s)
+	}
+}
+
+// Writes every diagnostic of the list in the given format.
+// The color mode is only used by the text format.
+func WriteDiagnostics(w io.Writer, list ErrorList, format Format, mode ColorMode) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, list)
+	case FormatSARIF:
+		return WriteSARIF(w, list)
+	default:
+		r := NewRenderer(w, mode)
+		for _, v := range list {
+			r.Render(v)
+		}
+		return nil
+	}
+}
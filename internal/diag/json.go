@@ -0,0 +1,108 @@
+package diag
+
+import (
+	"encoding/json"
+	"fracta/internal/token"
+	"io"
+)
+
+type jsonPosition struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type jsonSpan struct {
+	File  string       `json:"file"`
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonLabel struct {
+	Span    jsonSpan `json:"span"`
+	Message string   `json:"message"`
+}
+
+type jsonNote struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type jsonEdit struct {
+	Span    jsonSpan `json:"span"`
+	NewText string   `json:"newText"`
+}
+
+type jsonFix struct {
+	Message string     `json:"message"`
+	Edits   []jsonEdit `json:"edits"`
+}
+
+type jsonDiagnostic struct {
+	Severity string      `json:"severity"`
+	Code     string      `json:"code"`
+	Message  string      `json:"message"`
+	Span     *jsonSpan   `json:"span,omitempty"`
+	Labels   []jsonLabel `json:"labels"`
+	Notes    []jsonNote  `json:"notes"`
+	Fixes    []jsonFix   `json:"fixes"`
+}
+
+type jsonDocument struct {
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+}
+
+func toJSONPosition(p token.Position) jsonPosition {
+	return jsonPosition{Offset: p.Offset, Line: p.Line, Column: p.Column}
+}
+
+func toJSONSpan(s token.Span) jsonSpan {
+	return jsonSpan{
+		File:  s.File,
+		Start: toJSONPosition(s.Start),
+		End:   toJSONPosition(s.End),
+	}
+}
+
+// Writes the list as a single JSON document of the form {"diagnostics": [...]}
+func WriteJSON(w io.Writer, list ErrorList) error {
+	doc := jsonDocument{Diagnostics: make([]jsonDiagnostic, 0, len(list))}
+
+	for _, e := range list {
+		d := jsonDiagnostic{
+			Severity: e.Severity.String(),
+			Code:     string(e.Code),
+			Message:  e.Message,
+			Labels:   make([]jsonLabel, 0, len(e.Labels)),
+			Notes:    make([]jsonNote, 0, len(e.Notes)),
+			Fixes:    make([]jsonFix, 0, len(e.Fixes)),
+		}
+
+		if e.Span.IsValid() {
+			span := toJSONSpan(e.Span)
+			d.Span = &span
+		}
+
+		for _, v := range e.Labels {
+			d.Labels = append(d.Labels, jsonLabel{Span: toJSONSpan(v.Span), Message: v.Message})
+		}
+
+		for _, v := range e.Notes {
+			d.Notes = append(d.Notes, jsonNote{Severity: v.Severity.String(), Message: v.Message})
+		}
+
+		for _, v := range e.Fixes {
+			fix := jsonFix{Message: v.Message, Edits: make([]jsonEdit, 0, len(v.Edits))}
+			for _, ed := range v.Edits {
+				fix.Edits = append(fix.Edits, jsonEdit{Span: toJSONSpan(ed.Span), NewText: ed.NewText})
+			}
+			d.Fixes = append(d.Fixes, fix)
+		}
+
+		doc.Diagnostics = append(doc.Diagnostics, d)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,281 @@
+package diag
+
+import (
+	"fmt"
+	"fracta/internal/token"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Controls whether rendered diagnostics use ANSI colors
+type ColorMode int
+
+const (
+	ColorAuto   ColorMode = iota // Color only when writing to a terminal
+	ColorAlways                  // Always color
+	ColorNever                   // Never color
+)
+
+func ParseColorMode(s string) (ColorMode, error) {
+	switch s {
+	case "auto":
+		return ColorAuto, nil
+	case "always":
+		return ColorAlways, nil
+	case "never":
+		return ColorNever, nil
+	default:
+		return ColorAuto, fmt.Errorf("invalid color mode %q, expected auto, always or never", s)
+	}
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+)
+
+func severityStyle(s Severity) string {
+	switch s {
+	case SeverityError:
+		return ansiBold + ansiRed
+	case SeverityWarning:
+		return ansiBold + ansiYellow
+	case SeverityNote:
+		return ansiBold + ansiGreen
+	default:
+		return ansiBold + ansiCyan
+	}
+}
+
+const tabWidth = 4
+
+// Renders diagnostics as human-readable text with source excerpts
+type Renderer struct {
+	w       io.Writer
+	color   bool
+	sources map[string][]string
+}
+
+func NewRenderer(w io.Writer, mode ColorMode) *Renderer {
+	return &Renderer{
+		w:       w,
+		color:   useColor(w, mode),
+		sources: map[string][]string{},
+	}
+}
+
+func useColor(w io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Registers the contents of a source file, instead of reading it from disk when needed
+func (r *Renderer) AddSource(file string, src []byte) {
+	r.sources[file] = splitLines(string(src))
+}
+
+func (r *Renderer) sourceLines(file string) []string {
+	if lines, ok := r.sources[file]; ok {
+		return lines
+	}
+
+	var lines []string
+	if src, err := os.ReadFile(file); err == nil {
+		lines = splitLines(string(src))
+	}
+
+	r.sources[file] = lines
+	return lines
+}
+
+func splitLines(src string) []string {
+	lines := strings.Split(src, "\n")
+	for i, v := range lines {
+		lines[i] = strings.TrimSuffix(v, "\r")
+	}
+	return lines
+}
+
+func (r *Renderer) paint(style, s string) string {
+	if !r.color || s == "" {
+		return s
+	}
+	return style + s + ansiReset
+}
+
+type annotation struct {
+	span    token.Span
+	message string
+	primary bool
+	style   string
+}
+
+// Writes a single diagnostic
+func (r *Renderer) Render(e *ErrorContainer) {
+	header := e.Severity.String()
+	if e.Code != "" {
+		header += "[" + string(e.Code) + "]"
+	}
+	_, _ = fmt.Fprintf(r.w, "%s%s\n", r.paint(severityStyle(e.Severity), header), r.paint(ansiBold, ": "+e.Message))
+
+	anns := make([]annotation, 0, len(e.Labels)+1)
+	if e.Span.IsValid() {
+		anns = append(anns, annotation{span: e.Span, primary: true, style: severityStyle(e.Severity)})
+	}
+	for _, v := range e.Labels {
+		if v.Span.IsValid() {
+			anns = append(anns, annotation{span: v.Span, message: v.Message, style: ansiBold + ansiBlue})
+		}
+	}
+
+	gutter := 0
+	for _, v := range anns {
+		gutter = max(gutter, len(strconv.Itoa(v.span.Start.Line)))
+	}
+
+	// Annotations are grouped by file, the primary span's file goes first
+	files := make([]string, 0)
+	for _, v := range anns {
+		if !slices.Contains(files, v.span.File) {
+			files = append(files, v.span.File)
+		}
+	}
+
+	for i, file := range files {
+		group := make([]annotation, 0)
+		for _, v := range anns {
+			if v.span.File == file {
+				group = append(group, v)
+			}
+		}
+
+		arrow := "-->"
+		if i != 0 {
+			arrow = ":::"
+		}
+		_, _ = fmt.Fprintf(r.w, "%s%s %s\n", strings.Repeat(" ", gutter), r.paint(ansiBold+ansiBlue, arrow), group[0].span.String())
+
+		slices.SortStableFunc(group, func(a, b annotation) int {
+			if a.span.Start.Line != b.span.Start.Line {
+				return a.span.Start.Line - b.span.Start.Line
+			}
+			return a.span.Start.Column - b.span.Start.Column
+		})
+		r.renderSnippet(group, gutter)
+	}
+
+	notes := slices.Clone(e.Notes)
+	for _, v := range e.Fixes {
+		notes = append(notes, Note{Severity: SeverityHelp, Message: v.Message})
+	}
+
+	for _, v := range notes {
+		label := v.Severity.String() + ": "
+		_, _ = fmt.Fprintf(r.w, "%s %s %s\n", strings.Repeat(" ", gutter), r.paint(ansiBold+ansiBlue, "="), r.paint(ansiBold, label)+v.Message)
+	}
+
+	_, _ = fmt.Fprintln(r.w)
+}
+
+func (r *Renderer) renderSnippet(anns []annotation, gutter int) {
+	lines := r.sourceLines(anns[0].span.File)
+	bar := r.paint(ansiBold+ansiBlue, "|")
+	empty := strings.Repeat(" ", gutter) + " " + bar
+
+	_, _ = fmt.Fprintln(r.w, empty)
+
+	lastLine := 0
+	for _, v := range anns {
+		line := v.span.Start.Line
+		if line > len(lines) {
+			continue
+		}
+
+		src := lines[line-1]
+
+		if line != lastLine {
+			if lastLine != 0 && line > lastLine+1 {
+				_, _ = fmt.Fprintln(r.w, r.paint(ansiBold+ansiBlue, "..."))
+			}
+			num := fmt.Sprintf("%*d", gutter, line)
+			_, _ = fmt.Fprintf(r.w, "%s %s %s\n", r.paint(ansiBold+ansiBlue, num), bar, expandTabs(src))
+			lastLine = line
+		}
+
+		startCol := v.span.Start.Column
+		endCol := v.span.End.Column
+		if v.span.End.Line != line {
+			endCol = len([]rune(src)) + 1
+		}
+
+		prefix := displayWidth(src, 1, startCol)
+		width := max(displayWidth(src, startCol, endCol), 1)
+
+		marker := "-"
+		if v.primary {
+			marker = "^"
+		}
+
+		underline := strings.Repeat(marker, width)
+		if v.message != "" {
+			underline += " " + v.message
+		}
+
+		_, _ = fmt.Fprintf(r.w, "%s %s\n", empty, strings.Repeat(" ", prefix)+r.paint(v.style, underline))
+	}
+
+	_, _ = fmt.Fprintln(r.w, empty)
+}
+
+// Returns the display width of the runes of line between the from and to columns
+func displayWidth(line string, from, to int) int {
+	width := 0
+	col := 1
+	for _, c := range line {
+		if col >= to {
+			break
+		}
+		if col >= from {
+			if c == '\t' {
+				width += tabWidth
+			} else {
+				width++
+			}
+		}
+		col++
+	}
+
+	// Columns past the end of the line, e.g. a span pointing at the end of file
+	if to > col {
+		width += to - max(col, from)
+	}
+	return width
+}
+
+func expandTabs(s string) string {
+	return strings.ReplaceAll(s, "\t", strings.Repeat(" ", tabWidth))
+}
@@ -0,0 +1,226 @@
+package diag
+
+import (
+	"encoding/json"
+	"fracta/internal/token"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+	ByteOffset  int `json:"byteOffset"`
+	ByteLength  int `json:"byteLength"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifText            `json:"message,omitempty"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion `json:"deletedRegion"`
+	InsertedContent sarifText   `json:"insertedContent"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifFix struct {
+	Description     sarifText             `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifText       `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix      `json:"fixes,omitempty"`
+	Properties       map[string]any  `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifText          `json:"shortDescription"`
+	FullDescription      sarifText          `json:"fullDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func sarifArtifact(file string) sarifArtifactLocation {
+	if filepath.IsAbs(file) {
+		u := url.URL{Scheme: "file", Path: filepath.ToSlash(file)}
+		return sarifArtifactLocation{URI: u.String()}
+	}
+
+	u := url.URL{Path: filepath.ToSlash(file)}
+	return sarifArtifactLocation{URI: u.String(), URIBaseID: "%SRCROOT%"}
+}
+
+func toSarifRegion(s token.Span) sarifRegion {
+	return sarifRegion{
+		StartLine:   s.Start.Line,
+		StartColumn: s.Start.Column,
+		EndLine:     s.End.Line,
+		EndColumn:   s.End.Column,
+		ByteOffset:  s.Start.Offset,
+		ByteLength:  s.End.Offset - s.Start.Offset,
+	}
+}
+
+func toSarifLocation(s token.Span) sarifLocation {
+	loc := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifact(s.File)},
+	}
+	if s.IsValid() {
+		region := toSarifRegion(s)
+		loc.PhysicalLocation.Region = &region
+	}
+	return loc
+}
+
+// Writes the list as a SARIF 2.1.0 log with a single run.
+// Every known diagnostic code is listed as a rule of the tool.
+func WriteSARIF(w io.Writer, list ErrorList) error {
+	codes := AllCodes()
+	rules := make([]sarifRule, 0, len(codes))
+
+	for _, c := range codes {
+		info := codeTable[c]
+		rules = append(rules, sarifRule{
+			ID:                   string(c),
+			ShortDescription:     sarifText{Text: info.title},
+			FullDescription:      sarifText{Text: info.explanation},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(c.Severity())},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(list))
+
+	for _, e := range list {
+		res := sarifResult{
+			RuleID:    string(e.Code),
+			RuleIndex: slices.Index(codes, e.Code),
+			Level:     sarifLevel(e.Severity),
+			Message:   sarifText{Text: e.Message},
+		}
+
+		if e.Span.File != "" {
+			res.Locations = []sarifLocation{toSarifLocation(e.Span)}
+		}
+
+		for i, v := range e.Labels {
+			loc := toSarifLocation(v.Span)
+			id := i + 1
+			loc.ID = &id
+			loc.Message = &sarifText{Text: v.Message}
+			res.RelatedLocations = append(res.RelatedLocations, loc)
+		}
+
+		for _, v := range e.Fixes {
+			fix := sarifFix{Description: sarifText{Text: v.Message}}
+			for _, ed := range v.Edits {
+				fix.ArtifactChanges = append(fix.ArtifactChanges, sarifArtifactChange{
+					ArtifactLocation: sarifArtifact(ed.Span.File),
+					Replacements: []sarifReplacement{{
+						DeletedRegion:   toSarifRegion(ed.Span),
+						InsertedContent: sarifText{Text: ed.NewText},
+					}},
+				})
+			}
+			res.Fixes = append(res.Fixes, fix)
+		}
+
+		if len(e.Notes) != 0 {
+			notes := make([]string, 0, len(e.Notes))
+			for _, v := range e.Notes {
+				notes = append(notes, v.Severity.String()+": "+v.Message)
+			}
+			res.Properties = map[string]any{"notes": notes}
+		}
+
+		results = append(results, res)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:  "fracta",
+				Rules: rules,
+			}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
@@ -0,0 +1,26 @@
+package diag
+
+// Represents how serious a diagnostic is
+type Severity int
+
+const (
+	SeverityError   Severity = iota // Stops compilation
+	SeverityWarning                 // Reported, but compilation goes on
+	SeverityNote                    // Additional information
+	SeverityHelp                    // Suggestion on how to fix an issue
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityNote:
+		return "note"
+	case SeverityHelp:
+		return "help"
+	default:
+		return "unknown"
+	}
+}
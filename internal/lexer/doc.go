@@ -0,0 +1,42 @@
+package lexer
+
+import "strings"
+
+// Extracts the text of a '///' or '/** */' doc comment.
+// Returns false if the comment is a regular one, like '////' or '/***/'.
+func docCommentText(comment string) (string, bool) {
+	switch {
+	case strings.HasPrefix(comment, "///") && !strings.HasPrefix(comment, "////"):
+		text := strings.TrimSuffix(comment[3:], "\r")
+		return strings.TrimPrefix(text, " "), true
+
+	case strings.HasPrefix(comment, "/**") && !strings.HasPrefix(comment, "/***") && len(comment) > len("/**/"):
+		return blockDocText(comment[3 : len(comment)-2]), true
+
+	default:
+		return "", false
+	}
+}
+
+// Removes the indentation and leading '*' of every line of a block doc comment,
+// along with blank lines at both ends
+func blockDocText(body string) string {
+	lines := strings.Split(body, "\n")
+
+	for i, v := range lines {
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "*") {
+			v = strings.TrimPrefix(v[1:], " ")
+		}
+		lines[i] = v
+	}
+
+	for len(lines) != 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
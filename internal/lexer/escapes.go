@@ -0,0 +1,168 @@
+package lexer
+
+import (
+	"fmt"
+	"fracta/internal/diag"
+	tok "fracta/internal/token"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var simpleEscapes = map[rune]rune{
+	'a':  '\a',
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+	'\\': '\\',
+	'\'': '\'',
+	'"':  '"',
+}
+
+// Decodes the escape sequences of a literal body starting at the given position.
+// Errors are reported with the exact span of the offending escape, and decoding goes on
+// after them so every bad escape of a literal is reported. Returns false if there was any.
+//
+// In strings, \xNN and octal escapes stand for a single byte. Character literals hold
+// a code point, so there they are limited to ASCII.
+func (l *Lexer) decodeEscapes(body string, start tok.Position, char bool) (string, bool) {
+	var sb strings.Builder
+	ok := true
+	pos := start
+
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRuneInString(body[i:])
+
+		if r != '\\' {
+			sb.WriteString(body[i : i+size])
+			i += size
+			pos = advancePosition(pos, r, size)
+			continue
+		}
+
+		n, value, isByte, err := l.decodeEscape(body[i:])
+		end := pos
+		for _, v := range body[i : i+n] {
+			end = advancePosition(end, v, utf8.RuneLen(v))
+		}
+		span := tok.Span{File: l.filename, Start: pos, End: end}
+
+		switch {
+		case err != nil:
+			l.addErrorSpan(err.code, span, "%s", err.msg)
+			ok = false
+		case isByte && char && value > utf8.RuneSelf-1:
+			l.addErrorSpan(diag.ErrInvalidEscape, span, "byte escape %s is not ASCII, write the code point as \\u{%X} in character literals", body[i:i+n], value)
+			ok = false
+		case isByte:
+			sb.WriteByte(byte(value))
+		default:
+			sb.WriteRune(rune(value))
+		}
+
+		i += n
+		pos = end
+	}
+
+	return sb.String(), ok
+}
+
+type escapeError struct {
+	code diag.Code
+	msg  string
+}
+
+// Decodes the escape sequence at the start of s, which begins with a backslash.
+// Returns the length in bytes of the sequence, its value, and whether that value is a byte.
+func (l *Lexer) decodeEscape(s string) (int, uint32, bool, *escapeError) {
+	if len(s) < 2 {
+		return len(s), 0, false, &escapeError{diag.ErrInvalidEscape, "incomplete escape sequence"}
+	}
+
+	r, size := utf8.DecodeRuneInString(s[1:])
+	n := 1 + size
+
+	if v, ok := simpleEscapes[r]; ok {
+		return n, uint32(v), false, nil
+	}
+
+	switch {
+	case r == 'x':
+		digits := prefixLength(s[2:], 2, isHexDigit)
+		if digits != 2 {
+			return 2 + digits, 0, false, &escapeError{diag.ErrInvalidEscape, `\x escape needs exactly two hex digits, like \x7F`}
+		}
+		v, _ := strconv.ParseUint(s[2:4], 16, 8)
+		return 4, uint32(v), true, nil
+
+	case r >= '0' && r <= '7':
+		digits := prefixLength(s[1:], 3, isOctalDigit)
+		v, _ := strconv.ParseUint(s[1:1+digits], 8, 32)
+		if v > 0o377 {
+			return 1 + digits, 0, false, &escapeError{diag.ErrInvalidEscape, "octal escape " + s[:1+digits] + " is larger than \\377"}
+		}
+		return 1 + digits, uint32(v), true, nil
+
+	case r == 'u':
+		if len(s) < 3 || s[2] != '{' {
+			return 2, 0, false, &escapeError{diag.ErrInvalidEscape, `\u escape must be written as \u{...} with 1 to 6 hex digits`}
+		}
+
+		digits := prefixLength(s[3:], len(s), isHexDigit)
+		end := 3 + digits
+		if end >= len(s) || s[end] != '}' {
+			return end, 0, false, &escapeError{diag.ErrInvalidEscape, `unterminated \u{...} escape, expected '}'`}
+		}
+		if digits == 0 || digits > 6 {
+			return end + 1, 0, false, &escapeError{diag.ErrInvalidEscape, `\u{...} escape must contain 1 to 6 hex digits`}
+		}
+
+		v, _ := strconv.ParseUint(s[3:end], 16, 32)
+		switch {
+		case v >= 0xD800 && v <= 0xDFFF:
+			return end + 1, 0, false, &escapeError{diag.ErrInvalidCodePoint, codePointName(v) + " is a surrogate, which is not a valid code point"}
+		case v > utf8.MaxRune:
+			return end + 1, 0, false, &escapeError{diag.ErrInvalidCodePoint, codePointName(v) + " is out of range, the largest code point is U+10FFFF"}
+		}
+		return end + 1, uint32(v), false, nil
+
+	default:
+		return n, 0, false, &escapeError{diag.ErrInvalidEscape, "unknown escape sequence " + strconv.Quote(s[:n])}
+	}
+}
+
+func codePointName(v uint64) string {
+	return fmt.Sprintf("U+%04X", v)
+}
+
+// Returns how many of the first max bytes of s satisfy pred
+func prefixLength(s string, max int, pred func(byte) bool) int {
+	n := 0
+	for n < len(s) && n < max && pred(s[n]) {
+		n++
+	}
+	return n
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isOctalDigit(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// Returns the position right after rune r, which is size bytes long
+func advancePosition(p tok.Position, r rune, size int) tok.Position {
+	p.Offset += size
+	if r == '\n' {
+		p.Line++
+		p.Column = 1
+	} else {
+		p.Column++
+	}
+	return p
+}
@@ -1,44 +1,83 @@
 package lexer
 
 import (
+	"errors"
 	"fmt"
 	"fracta/internal/diag"
 	tok "fracta/internal/token"
-	"io"
-	"strconv"
+	"iter"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 var punctuations = map[string]tok.TokenType{
-	"+":  tok.TokOpPlus,
-	"-":  tok.TokOpMinus,
-	"*":  tok.TokOpStar,
-	"/":  tok.TokOpSlash,
-	"%":  tok.TokOpMod,
-	"=":  tok.TokOpAssign,
-	"==": tok.TokOpEq,
-	"!=": tok.TokOpNotEq,
-	"<":  tok.TokOpLessThan,
-	">":  tok.TokOpGreaterThan,
-	"<=": tok.TokOpLessEqual,
-	">=": tok.TokOpGreaterEqual,
-	"(":  tok.TokOpenParen,
-	")":  tok.TokCloseParen,
-	"[":  tok.TokOpenSquare,
-	"]":  tok.TokCloseSquare,
-	"{":  tok.TokOpenBracket,
-	"}":  tok.TokCloseBracket,
-	".":  tok.TokOpDot,
-	":":  tok.TokOpColon,
-	"::": tok.TokOpDoubleColon,
-	",":  tok.TokOpComma,
-	";":  tok.TokSemicolon,
+	"+":   tok.TokOpPlus,
+	"-":   tok.TokOpMinus,
+	"*":   tok.TokOpStar,
+	"/":   tok.TokOpSlash,
+	"%":   tok.TokOpMod,
+	"=":   tok.TokOpAssign,
+	"+=":  tok.TokOpPlusAssign,
+	"-=":  tok.TokOpMinusAssign,
+	"*=":  tok.TokOpStarAssign,
+	"/=":  tok.TokOpSlashAssign,
+	"%=":  tok.TokOpModAssign,
+	"&=":  tok.TokOpAmpersandAssign,
+	"|=":  tok.TokOpPipeAssign,
+	"^=":  tok.TokOpCaretAssign,
+	"<<=": tok.TokOpShiftLeftAssign,
+	">>=": tok.TokOpShiftRightAssign,
+	"==":  tok.TokOpEq,
+	"!=":  tok.TokOpNotEq,
+	"<":   tok.TokOpLessThan,
+	">":   tok.TokOpGreaterThan,
+	"<=":  tok.TokOpLessEqual,
+	">=":  tok.TokOpGreaterEqual,
+	"&&":  tok.TokOpLogicalAnd,
+	"||":  tok.TokOpLogicalOr,
+	"!":   tok.TokOpBang,
+	"&":   tok.TokOpAmpersand,
+	"|":   tok.TokOpPipe,
+	"^":   tok.TokOpCaret,
+	"~":   tok.TokOpTilde,
+	"<<":  tok.TokOpShiftLeft,
+	">>":  tok.TokOpShiftRight,
+	"(":   tok.TokOpenParen,
+	")":   tok.TokCloseParen,
+	"[":   tok.TokOpenSquare,
+	"]":   tok.TokCloseSquare,
+	"{":   tok.TokOpenBracket,
+	"}":   tok.TokCloseBracket,
+	".":   tok.TokOpDot,
+	":":   tok.TokOpColon,
+	"::":  tok.TokOpDoubleColon,
+	",":   tok.TokOpComma,
+	";":   tok.TokSemicolon,
 }
 
 var keywords = map[string]tok.TokenType{
-	"func":   tok.TokKwFunc,
-	"return": tok.TokKwReturn,
+	"func":     tok.TokKwFunc,
+	"return":   tok.TokKwReturn,
+	"true":     tok.TokKwTrue,
+	"false":    tok.TokKwFalse,
+	"null":     tok.TokKwNull,
+	"var":      tok.TokKwVar,
+	"let":      tok.TokKwLet,
+	"if":       tok.TokKwIf,
+	"else":     tok.TokKwElse,
+	"while":    tok.TokKwWhile,
+	"for":      tok.TokKwFor,
+	"loop":     tok.TokKwLoop,
+	"break":    tok.TokKwBreak,
+	"continue": tok.TokKwContinue,
+	"as":       tok.TokKwAs,
+}
+
+// Literal values of the keywords that stand for one
+var keywordValues = map[tok.TokenType]any{
+	tok.TokKwTrue:  true,
+	tok.TokKwFalse: false,
 }
 
 type matchInfo struct {
@@ -93,11 +132,26 @@ func matchPunctuation(src string) matchResult {
 	}
 }
 
-func (l *Lexer) addError(f string, v ...any) {
+// Returns the span from start up to the current position
+func (l *Lexer) spanFrom(start tok.Position) tok.Span {
+	return tok.Span{
+		File:  l.filename,
+		Start: start,
+		End:   l.pos,
+	}
+}
+
+func (l *Lexer) addError(code diag.Code, f string, v ...any) *diag.ErrorContainer {
 	msg := fmt.Sprintf(f, v...)
-	other := diag.CreateError(msg, l.filename, l.currentLine)
+	other := diag.CreateError(code, msg, l.spanFrom(l.tokStart))
 
 	l.errors = append(l.errors, other)
+	return other
+}
+
+func (l *Lexer) addErrorSpan(code diag.Code, span tok.Span, f string, v ...any) {
+	msg := fmt.Sprintf(f, v...)
+	l.errors = append(l.errors, diag.CreateError(code, msg, span))
 }
 
 // Closes the file handle, if any
@@ -111,75 +165,93 @@ func (l *Lexer) Close() error {
 }
 
 func (l *Lexer) advance() rune {
-	if l.reader == nil {
-		return 0
-	}
+	var r rune
+	var size int
 
-	if l.peekedValid {
-		l.peekedValid = false
-		return l.peekedRune
-	}
+	if len(l.peeked) != 0 {
+		r, size = l.peeked[0].r, l.peeked[0].size
+		l.peeked = l.peeked[1:]
+	} else {
+		if l.reader == nil {
+			return 0
+		}
 
-	r, _, err := l.reader.ReadRune()
-	if err == io.EOF {
-		l.reader = nil
-		return 0
+		var err error
+		r, size, err = l.reader.ReadRune()
+		if err != nil {
+			l.reader = nil
+			return 0
+		}
 	}
 
-	if err != nil {
-		l.reader = nil
-		return 0
+	if l.recording {
+		l.raw.WriteRune(r)
 	}
+
+	l.pos = advancePosition(l.pos, r, size)
 	return r
 }
 
 func (l *Lexer) peek() rune {
-	if l.reader == nil {
-		return 0
-	}
+	return l.peekAt(0)
+}
 
-	if l.peekedValid {
-		return l.peekedRune
-	}
+// Returns the n-th rune after the current position without consuming it
+func (l *Lexer) peekAt(n int) rune {
+	for len(l.peeked) <= n {
+		if l.reader == nil {
+			return 0
+		}
 
-	r, _, err := l.reader.ReadRune()
-	if err == io.EOF {
-		l.reader = nil
-		return 0
-	}
-	if err != nil {
-		l.reader = nil
-		return 0
+		r, size, err := l.reader.ReadRune()
+		if err != nil {
+			l.reader = nil
+			return 0
+		}
+		l.peeked = append(l.peeked, peekedRune{r, size})
 	}
-	l.peekedRune = r
-	l.peekedValid = true
-	return r
+	return l.peeked[n].r
 }
 
-// Gets all the tokens, until an EOF is reached
+// Gets all the tokens, until an EOF is reached.
+// Lexing goes on after errors, so the token stream is returned even when the
+// error list is not nil. Invalid source is represented by TokError tokens.
 func (l *Lexer) GetAllTokens() ([]tok.Token, error) {
 	out := make([]tok.Token, 0)
-	for {
-		nw := l.GetToken()
+	for nw := range l.Tokens() {
 		out = append(out, nw)
-		if nw.Kind == tok.TokEndOfFile || nw.Kind == tok.TokError {
-			break
+	}
+
+	return out, l.Errors()
+}
+
+// Returns an iterator over the remaining tokens, the end of file token included.
+// Tokens are scanned lazily, errors are collected and can be read with Errors.
+func (l *Lexer) Tokens() iter.Seq[tok.Token] {
+	return func(yield func(tok.Token) bool) {
+		for {
+			nw := l.GetToken()
+			if !yield(nw) || nw.Kind == tok.TokEndOfFile {
+				return
+			}
 		}
 	}
+}
 
+// Returns the errors found so far as a diag.ErrorList, or nil if there are none
+func (l *Lexer) Errors() error {
 	if len(l.errors) != 0 {
-		return nil, diag.ErrorList(l.errors)
+		return diag.ErrorList(l.errors)
 	}
-
-	return out, nil
+	return nil
 }
 
 func (l *Lexer) GetToken() tok.Token {
 	var out tok.Token
 
-	if l.reader == nil {
+	if !l.IsOpen() {
 		out.Kind = tok.TokEndOfFile
-		out.Line = l.currentLine
+		out.Span = l.spanFrom(l.pos)
 		return out
 	}
 
@@ -188,66 +260,130 @@ func (l *Lexer) GetToken() tok.Token {
 }
 
 func (l *Lexer) ScanToken(t *tok.Token) {
-	c := l.advance()
+	// Found while scanning trailing trivia, it becomes an error token like in the default mode
+	if c := l.unterminated; c != nil {
+		l.unterminated = nil
+		t.Kind = tok.TokError
+		t.Lexeme = c.Text
+		t.Span = c.Span
+		return
+	}
 
-	if c == 0 {
-		t.Kind = tok.TokEndOfFile
-		t.Line = l.currentLine
+	l.recording = l.keepTrivia
+	leading, doc, ok := l.scanTrivia(false)
+	t.Doc = doc
+
+	if ok {
+		l.tokStart = l.pos
+		l.raw.Reset()
+		l.scanTokenBody(t)
+	} else {
+		t.Kind = tok.TokError
+	}
+	t.Span = l.spanFrom(l.tokStart)
+
+	if !l.keepTrivia {
 		return
 	}
 
-	// Skip whitespace and handle comments
+	t.Lexeme = l.raw.String()
+	t.LeadingTrivia = leading
+	if t.Kind != tok.TokEndOfFile {
+		t.TrailingTrivia, _, _ = l.scanTrivia(true)
+	}
+}
+
+// Consumes whitespace and comments before the next token, which are only kept in trivia mode.
+// Trailing trivia stops before a line feed. Also returns the doc comment right before the token.
+// Returns false on an unterminated block comment, which is then reported as an error token.
+func (l *Lexer) scanTrivia(trailing bool) ([]tok.Trivia, string, bool) {
+	var out []tok.Trivia
+
+	// Doc comments only document the token right after them, a blank line
+	// or a regular comment in between detaches them
+	var docs []string
+	newlines := 0
+
+	// Comment text is needed for doc comments even outside of trivia mode
+	defer func(rec bool) { l.recording = rec }(l.recording)
+
 	for {
-		switch c {
+		start := l.pos
+		l.raw.Reset()
+
+		var kind tok.TriviaKind
+
+		switch l.peek() {
 		case ' ', '\t', '\r':
-			c = l.advance()
+			kind = tok.TriviaWhitespace
+			for r := l.peek(); r == ' ' || r == '\t' || r == '\r'; r = l.peek() {
+				_ = l.advance()
+			}
 		case '\n':
-			l.currentLine++
-			c = l.advance()
+			if trailing {
+				return out, strings.Join(docs, "\n"), true
+			}
+			kind = tok.TriviaNewline
+			_ = l.advance()
+
+			newlines++
+			if newlines > 1 {
+				docs = nil
+			}
 		case '/':
-			r := l.peek()
-			if r == '/' { // line comment
-				_ = l.advance()
-				for {
-					r2 := l.advance()
-					if r2 == 0 || r2 == '\n' {
-						if r2 == '\n' {
-							l.currentLine++
-						}
-						c = l.advance()
-						break
-					}
+			l.recording = true
+
+			switch l.peekAt(1) {
+			case '/':
+				kind = tok.TriviaLineComment
+				for r := l.peek(); r != 0 && r != '\n' && !(r == '\r' && l.peekAt(1) == '\n'); r = l.peek() {
+					_ = l.advance()
 				}
-			} else if r == '*' { // block comment
-				_ = l.advance()
+			case '*':
+				kind = tok.TriviaBlockComment
+				_, _ = l.advance(), l.advance()
 				for {
-					r2 := l.advance()
-					if r2 == 0 {
-						t.Kind = tok.TokError
-						t.Line = l.currentLine
-						l.addError("unterminated block comment")
-						return
-					}
-					if r2 == '\n' {
-						l.currentLine++
+					r := l.advance()
+					if r == 0 {
+						l.tokStart = start
+						l.addError(diag.ErrUnterminatedComment, "unterminated block comment")
+						if trailing {
+							l.unterminated = &tok.Trivia{Kind: kind, Text: l.raw.String(), Span: l.spanFrom(start)}
+						}
+						return out, "", false
 					}
-					if r2 == '*' && l.peek() == '/' {
+					if r == '*' && l.peek() == '/' {
 						_ = l.advance()
 						break
 					}
 				}
-				c = l.advance()
+			default:
+				return out, strings.Join(docs, "\n"), true
+			}
+
+			if doc, ok := docCommentText(l.raw.String()); ok {
+				docs = append(docs, doc)
 			} else {
-				goto doneSkipping
+				docs = nil
 			}
+			newlines = 0
+			l.recording = l.keepTrivia
 		default:
-			goto doneSkipping
+			return out, strings.Join(docs, "\n"), true
+		}
+
+		if l.keepTrivia {
+			out = append(out, tok.Trivia{Kind: kind, Text: l.raw.String(), Span: l.spanFrom(start)})
 		}
 	}
-doneSkipping:
+}
+
+// Scans a single token, starting at the current position
+func (l *Lexer) scanTokenBody(t *tok.Token) {
+	c := l.advance()
+
 	if c == 0 {
 		t.Kind = tok.TokEndOfFile
-		t.Line = l.currentLine
 		return
 	}
 
@@ -266,6 +402,11 @@ doneSkipping:
 		return
 	}
 
+	if c == '`' {
+		l.scanRawString(t)
+		return
+	}
+
 	if c == '\'' {
 		l.scanCharLiteral(t)
 		return
@@ -275,8 +416,7 @@ doneSkipping:
 	biggestMatch := ""
 	res := matchPunctuation(proc)
 	if res == mNone {
-		t.Kind = tok.TokError
-		t.Line = l.currentLine
+		l.scanInvalidCharacters(t, proc)
 		return
 	}
 	switch res {
@@ -284,7 +424,6 @@ doneSkipping:
 		biggestMatch = proc
 	case mFullMatch:
 		t.Kind = punctuations[proc]
-		t.Line = l.currentLine
 		return
 	}
 
@@ -294,32 +433,65 @@ doneSkipping:
 			break
 		}
 
-		proc += string(r)
-		res = matchPunctuation(proc)
+		next := proc + string(r)
+		res = matchPunctuation(next)
 
-		switch res {
-		case mNone:
-			if _, ok := punctuations[biggestMatch]; !ok {
-				t.Kind = tok.TokError
-				t.Line = l.currentLine
-				return
-			}
-			t.Kind = punctuations[biggestMatch]
-			t.Line = l.currentLine
-			return
-		case mPartial:
-			_ = l.advance()
-			continue
-		case mMatchButLongerPossible:
+		if res == mNone {
+			break
+		}
+
+		_ = l.advance()
+		proc = next
+
+		if res == mMatchButLongerPossible || res == mFullMatch {
 			biggestMatch = proc
-			_ = l.advance()
-			continue
-		case mFullMatch:
-			_ = l.advance()
-			t.Kind = punctuations[proc]
-			t.Line = l.currentLine
+		}
+		if res == mFullMatch {
+			break
+		}
+	}
+
+	kind, ok := punctuations[biggestMatch]
+	if !ok {
+		l.scanInvalidCharacters(t, proc)
+		return
+	}
+	t.Kind = kind
+}
+
+// Consumes a run of characters that cannot start any token, and reports them as a single error
+func (l *Lexer) scanInvalidCharacters(t *tok.Token, first string) {
+	var sb strings.Builder
+	sb.WriteString(first)
+
+	for {
+		r := l.peek()
+		if r == 0 || canStartToken(r) {
+			break
+		}
+		_ = l.advance()
+		sb.WriteRune(r)
+	}
+
+	t.Kind = tok.TokError
+	t.Lexeme = sb.String()
+
+	if len([]rune(t.Lexeme)) == 1 {
+		l.addError(diag.ErrUnexpectedCharacter, "unexpected character %q", t.Lexeme)
+	} else {
+		l.addError(diag.ErrUnexpectedCharacter, "unexpected characters %q", t.Lexeme)
+	}
+}
+
+// Consumes the rest of a malformed literal, so lexing resumes at the next token boundary
+func (l *Lexer) skipLiteralTail(sb *strings.Builder) {
+	for {
+		r := l.peek()
+		if r == 0 || !(isIdentifierPart(r) || r == '.') {
 			return
 		}
+		_ = l.advance()
+		sb.WriteRune(r)
 	}
 }
 
@@ -340,13 +512,13 @@ func (l *Lexer) scanKeywordOrIdentifier(t *tok.Token, c rune) {
 
 	if k, ok := keywords[lex]; ok {
 		t.Kind = k
+		t.Value = keywordValues[k]
 	} else {
 		t.Kind = tok.TokIdentifier
 		t.Lexeme = lex
 		t.Identifier = lex
 	}
 
-	t.Line = l.currentLine
 }
 
 func (l *Lexer) scanNumberLiteral(t *tok.Token, first rune) {
@@ -355,6 +527,7 @@ func (l *Lexer) scanNumberLiteral(t *tok.Token, first rune) {
 
 	seenDot := first == '.'
 	seenExp := false
+	hex := first == '0' && l.peek() == 'x'
 
 	for {
 		r := l.peek()
@@ -368,10 +541,12 @@ func (l *Lexer) scanNumberLiteral(t *tok.Token, first rune) {
 			}
 			_ = l.advance() // consume the dot
 			next := l.peek()
-			if !isDigit(next) {
+			if !isDigit(next) && !(hex && next < utf8.RuneSelf && isHexDigit(byte(next))) {
+				sb.WriteRune('.')
+				l.skipLiteralTail(&sb)
 				t.Kind = tok.TokError
-				t.Line = l.currentLine
-				l.addError("invalid number literal: %q", sb.String())
+				t.Lexeme = sb.String()
+				l.addError(diag.ErrInvalidNumber, "invalid number literal %q: missing digit after decimal point", sb.String())
 				return
 			}
 			sb.WriteRune('.')
@@ -379,7 +554,7 @@ func (l *Lexer) scanNumberLiteral(t *tok.Token, first rune) {
 			continue
 		}
 
-		if r == 'e' || r == 'E' {
+		if (!hex && (r == 'e' || r == 'E')) || (hex && (r == 'p' || r == 'P')) {
 			if seenExp {
 				break
 			}
@@ -407,134 +582,40 @@ func (l *Lexer) scanNumberLiteral(t *tok.Token, first rune) {
 
 	if strings.HasSuffix(lit, ".") {
 		t.Kind = tok.TokError
-		t.Line = l.currentLine
-		l.addError("invalid number literal: %q", sb.String())
+		t.Lexeme = lit
+		l.addError(diag.ErrInvalidNumber, "invalid number literal %q: missing digit after decimal point", sb.String())
 		return
 	}
 
 	kind, val, err := ClassifyNumberLiteral(lit)
-	if err != nil {
+
+	var overflow *LiteralOverflowError
+	if errors.As(err, &overflow) {
 		t.Kind = tok.TokError
-		t.Line = l.currentLine
-		l.addError("invalid number literal: %q", sb.String())
+		t.Lexeme = lit
+		l.addError(diag.ErrLiteralOverflow, "%v", overflow).
+			WithNote("the range of %s is %s", overflow.Type, overflow.Range())
 		return
 	}
-
-	t.Kind = kind
-	t.Value = val
-	t.Lexeme = lit
-	t.Line = l.currentLine
-}
-
-func (l *Lexer) scanStringLiteral(t *tok.Token) {
-	var sb strings.Builder
-
-	for {
-		r := l.advance()
-		if r == 0 {
-			t.Kind = tok.TokError
-			t.Line = l.currentLine
-			l.addError("unterminated string literal")
-			return
-		}
-
-		if r == '\n' {
-			l.currentLine++
-		}
-
-		if r == '"' {
-			break
-		}
-
-		sb.WriteRune(r)
-	}
-
-	raw := `"` + sb.String() + `"`
-
-	val, err := strconv.Unquote(raw)
 	if err != nil {
 		t.Kind = tok.TokError
-		t.Line = l.currentLine
-		l.addError("invalid escape sequence")
+		t.Lexeme = lit
+		l.addError(diag.ErrInvalidNumber, "%v", err)
 		return
 	}
 
-	t.Kind = tok.TokString
+	t.Kind = kind
 	t.Value = val
-	t.Lexeme = raw
-	t.Line = l.currentLine
+	t.Lexeme = lit
 }
 
-func (l *Lexer) scanCharLiteral(t *tok.Token) {
-	var sb strings.Builder
-	sb.WriteRune('\'')
-
-	for {
-		r := l.advance()
-		if r == 0 {
-			t.Kind = tok.TokError
-			t.Line = l.currentLine
-			l.addError("unterminated character literal")
-			return
-		}
-
-		sb.WriteRune(r)
-
-		if r == '\n' {
-			l.currentLine++
-		}
-
-		if r == '\'' {
-			break
-		}
-
-		if r == '\\' {
-			r2 := l.advance()
-			if r2 == 0 {
-				t.Kind = tok.TokError
-				t.Line = l.currentLine
-				l.addError("unterminated escape sequence in character literal")
-				return
-			}
-			sb.WriteRune(r2)
-		}
-	}
-
-	raw := sb.String()
-
-	if len(raw) < 2 {
-		t.Kind = tok.TokError
-		t.Line = l.currentLine
-		l.addError("empty character literal")
-		return
+// Reports whether r is the first character of some valid token, or whitespace
+func canStartToken(r rune) bool {
+	if unicode.IsSpace(r) || isAlpha(r) || isDigit(r) || r == '"' || r == '`' || r == '\'' {
+		return true
 	}
-	content := raw[1 : len(raw)-1]
-
-	if content == "" {
-		t.Kind = tok.TokError
-		t.Line = l.currentLine
-		l.addError("empty character literal")
-		return
-	}
-
-	value, _, tail, err := strconv.UnquoteChar(content, '\'')
-	if err != nil {
-		t.Kind = tok.TokError
-		t.Line = l.currentLine
-		l.addError("invalid character literal: %v", err)
-		return
-	}
-	if tail != "" {
-		t.Kind = tok.TokError
-		t.Line = l.currentLine
-		l.addError("character literal must contain exactly one character")
-		return
-	}
-
-	t.Kind = tok.TokChar
-	t.Value = value
-	t.Lexeme = raw
-	t.Line = l.currentLine
+	_, ok := punctInfo[string(r)]
+	return ok
 }
 
 func isAlpha(r rune) bool {
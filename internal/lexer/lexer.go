@@ -3,23 +3,38 @@ package lexer
 import (
 	"bufio"
 	"fracta/internal/diag"
+	tok "fracta/internal/token"
 	"io"
 	"os"
+	"strings"
 )
 
 // Transforms valid Fracta source into a token stream
 type Lexer struct {
-	reader      *bufio.Reader
-	closer      io.Closer
-	currentLine int
-	filename    string
+	reader   *bufio.Reader
+	closer   io.Closer
+	filename string
 
-	peekedRune  rune
-	peekedValid bool
+	pos      tok.Position // Position of the next unread rune
+	tokStart tok.Position // Position where the token being scanned starts
+
+	peeked []peekedRune // Runes read ahead of pos, at most two
+
+	keepTrivia   bool
+	recording    bool            // Whether consumed source text goes into raw
+	raw          strings.Builder // Source text consumed since the last reset
+	unterminated *tok.Trivia     // Unterminated block comment found after a token in trivia mode
 
 	errors []*diag.ErrorContainer
 }
 
+type peekedRune struct {
+	r    rune
+	size int
+}
+
+var startPosition = tok.Position{Offset: 0, Line: 1, Column: 1}
+
 func NewLexerFromFile(path string) (*Lexer, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -27,10 +42,10 @@ func NewLexerFromFile(path string) (*Lexer, error) {
 	}
 
 	return &Lexer{
-		reader:      bufio.NewReader(f),
-		closer:      f,
-		currentLine: 1,
-		filename:    path,
+		reader:   bufio.NewReader(f),
+		closer:   f,
+		filename: path,
+		pos:      startPosition,
 
 		errors: make([]*diag.ErrorContainer, 0),
 	}, nil
@@ -38,14 +53,21 @@ func NewLexerFromFile(path string) (*Lexer, error) {
 
 func NewLexerFromReader(r io.Reader, name string) *Lexer {
 	return &Lexer{
-		reader:      bufio.NewReader(r),
-		currentLine: 1,
-		filename:    name,
+		reader:   bufio.NewReader(r),
+		filename: name,
+		pos:      startPosition,
 
 		errors: make([]*diag.ErrorContainer, 0),
 	}
 }
 
 func (l *Lexer) IsOpen() bool {
-	return l.reader != nil
+	return l.reader != nil || len(l.peeked) != 0 || l.unterminated != nil
+}
+
+// Enables or disables trivia mode.
+// In trivia mode comments and whitespace are attached to tokens, and every
+// token lexeme is its exact source text, so the original file can be rebuilt.
+func (l *Lexer) SetTriviaMode(enabled bool) {
+	l.keepTrivia = enabled
 }
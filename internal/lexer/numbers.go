@@ -1,13 +1,60 @@
 package lexer
 
 import (
+	"errors"
 	"fmt"
 	tk "fracta/internal/token"
+	"go/constant"
+	gotoken "go/token"
+	"math"
 	"strconv"
 	"strings"
 	"unicode"
 )
 
+// Returned by ClassifyNumberLiteral when the value does not fit in the type of the literal
+type LiteralOverflowError struct {
+	Literal string
+	Type    string // Fracta name of the type, like i8
+}
+
+func (e *LiteralOverflowError) Error() string {
+	return fmt.Sprintf("literal %s overflows %s", e.Literal, e.Type)
+}
+
+// Returns the range of values of the type as text
+func (e *LiteralOverflowError) Range() string {
+	switch e.Type {
+	case "f32":
+		return fmt.Sprintf("-%g to %g", math.MaxFloat32, math.MaxFloat32)
+	case "f64":
+		return fmt.Sprintf("-%g to %g", math.MaxFloat64, math.MaxFloat64)
+	}
+
+	bits, _ := strconv.Atoi(e.Type[1:])
+	if e.Type[0] == 'u' {
+		return fmt.Sprintf("0 to %d", uint64(1)<<bits-1)
+	}
+	return fmt.Sprintf("%d to %d", -(int64(1) << (bits - 1)), uint64(1)<<(bits-1)-1)
+}
+
+var literalTypeNames = map[tk.TokenType]string{
+	tk.TokI8:  "i8",
+	tk.TokI16: "i16",
+	tk.TokI32: "i32",
+	tk.TokI64: "i64",
+	tk.TokU8:  "u8",
+	tk.TokU16: "u16",
+	tk.TokU32: "u32",
+	tk.TokU64: "u64",
+	tk.TokF32: "f32",
+	tk.TokF64: "f64",
+}
+
+// Classifies a number literal and parses its value.
+// Digits may be separated by single underscores, as in 1_000 or 0x_FF_FF.
+// Values that do not fit the type given by the suffix yield a *LiteralOverflowError.
+// Literals without a suffix are untyped TokInt or TokFloat, with a constant.Value of any size.
 func ClassifyNumberLiteral(orig string) (tk.TokenType, any, error) {
 	lit := orig
 	i := 0
@@ -42,12 +89,14 @@ func ClassifyNumberLiteral(orig string) (tk.TokenType, any, error) {
 	//   EXP        digits of exponent
 	//
 	// Invalid:
-	//   - float in non-base10
-	//   - exponent in non-base10
+	//   - float in base 2 or 8
+	//   - exponent in base 2 or 8, hexadecimal uses p/P as e/E is a digit
+	//   - hexadecimal float without exponent
 	//   - 1.   (requires trailing fractional digit)
-	//   - underscore
+	//   - underscore not placed between two digits, or right after the base prefix
 	state := "INT"
 	isFloat := false
+	seenExp := false
 
 	acceptHex := "0123456789abcdefABCDEF"
 	//acceptDec := "0123456789"
@@ -70,7 +119,11 @@ func ClassifyNumberLiteral(orig string) (tk.TokenType, any, error) {
 		c := lit[i]
 
 		if c == '_' {
-			return tk.TokError, nil, fmt.Errorf("underscores are not allowed in numeric literals: %q", orig)
+			if !separatorAllowed(lit, i, state, base, digitOK) {
+				return tk.TokError, nil, fmt.Errorf("'_' must separate digits in literal %q", orig)
+			}
+			i++
+			continue
 		}
 
 		switch state {
@@ -81,28 +134,29 @@ func ClassifyNumberLiteral(orig string) (tk.TokenType, any, error) {
 				continue
 			}
 			if c == '.' {
-				if base != 10 {
-					return tk.TokError, nil, fmt.Errorf("floating literals are only allowed in decimal: %q", orig)
+				if base != 10 && base != 16 {
+					return tk.TokError, nil, fmt.Errorf("floating literals are only allowed in decimal and hexadecimal: %q", orig)
 				}
 				state = "DOT"
 				isFloat = true
 				i++
 				continue
 			}
-			if c == 'e' || c == 'E' {
-				if base != 10 {
-					return tk.TokError, nil, fmt.Errorf("exponents are only allowed in decimal numbers: %q", orig)
-				}
+			if isExponentMark(c, base) {
 				state = "EXP_MARK"
 				isFloat = true
+				seenExp = true
 				i++
 				continue
 			}
+			if c == 'e' || c == 'E' || c == 'p' || c == 'P' {
+				return tk.TokError, nil, fmt.Errorf("exponents are only allowed in decimal and hexadecimal numbers: %q", orig)
+			}
 			goto endNumber
 
 		case "DOT":
 			// must see at least one digit
-			if unicode.IsDigit(rune(c)) {
+			if digitOK(c) {
 				state = "FRAC"
 				i++
 				continue
@@ -110,12 +164,13 @@ func ClassifyNumberLiteral(orig string) (tk.TokenType, any, error) {
 			return tk.TokError, nil, fmt.Errorf("fraction requires at least one digit after '.': %q", orig)
 
 		case "FRAC":
-			if unicode.IsDigit(rune(c)) {
+			if digitOK(c) {
 				i++
 				continue
 			}
-			if c == 'e' || c == 'E' {
+			if isExponentMark(c, base) {
 				state = "EXP_MARK"
+				seenExp = true
 				i++
 				continue
 			}
@@ -153,7 +208,7 @@ func ClassifyNumberLiteral(orig string) (tk.TokenType, any, error) {
 
 endNumber:
 	numEnd := i
-	numPart := lit[:numEnd]
+	numPart := strings.ReplaceAll(lit[:numEnd], "_", "")
 	suffix := lit[numEnd:]
 
 	// Reject "1." (float with no digits after '.')
@@ -164,7 +219,7 @@ endNumber:
 			frac := numPart[dot+1:]
 
 			// strip exponent part from frac (if any)
-			if ei := strings.IndexAny(frac, "eE"); ei != -1 {
+			if ei := strings.IndexAny(frac, exponentMarks(base)); ei != -1 {
 				frac = frac[:ei] // remove exponent from this check
 			}
 
@@ -175,6 +230,10 @@ endNumber:
 	}
 
 	// ---------- Determine suffix type ----------
+	if isFloat && suffix != "" && suffix != "f" && suffix != "d" {
+		return tk.TokError, nil, fmt.Errorf("integer suffix %q on floating literal %q", suffix, orig)
+	}
+
 	var t tk.TokenType
 	switch suffix {
 	case "b":
@@ -201,19 +260,53 @@ endNumber:
 		isFloat = true
 	case "":
 		if isFloat {
-			t = tk.TokF64
+			t = tk.TokFloat
 		} else {
-			t = tk.TokI64
+			t = tk.TokInt
 		}
 	default:
 		return tk.TokError, nil, fmt.Errorf("unknown numeric suffix %q in literal %q", suffix, orig)
 	}
 
+	if numPart == "" {
+		return tk.TokError, nil, fmt.Errorf("literal %q has no digits", orig)
+	}
+
+	// Like in C, the exponent is what tells hexadecimal floats apart, as 'e' is a digit
+	if base == 16 && isFloat && !seenExp {
+		return tk.TokError, nil, fmt.Errorf("hexadecimal float literal %q requires a 'p' exponent", orig)
+	}
+
+	// ---------- Untyped constants ----------
+	// Kept exact, their type and range check come from the context they are used in
+	if t == tk.TokInt || t == tk.TokFloat {
+		kind := gotoken.INT
+		if isFloat {
+			kind = gotoken.FLOAT
+		}
+
+		prefix := orig[:len(orig)-len(lit)]
+		val := constant.MakeFromLiteral(prefix+numPart, kind, 0)
+		if val.Kind() == constant.Unknown {
+			return tk.TokError, nil, fmt.Errorf("invalid number literal %q", orig)
+		}
+		return t, val, nil
+	}
+
 	// ---------- Parse as float ----------
 	if isFloat {
-		val, err := strconv.ParseFloat(numPart, 64)
+		bits := 64
+		if t == tk.TokF32 {
+			bits = 32
+		}
+
+		if base == 16 {
+			numPart = "0x" + numPart
+		}
+
+		val, err := strconv.ParseFloat(numPart, bits)
 		if err != nil {
-			return tk.TokError, nil, fmt.Errorf("invalid float literal %q: %w", orig, err)
+			return tk.TokError, nil, literalError(orig, t, err)
 		}
 		if t == tk.TokF32 {
 			return t, float32(val), nil
@@ -222,33 +315,93 @@ endNumber:
 	}
 
 	// ---------- Parse as integer ----------
-	valSigned, errI := strconv.ParseInt(numPart, base, 64)
-	if errI == nil {
+	switch t {
+	case tk.TokI8, tk.TokI16, tk.TokI32, tk.TokI64:
+		val, err := strconv.ParseInt(numPart, base, literalBits(t))
+		if err != nil {
+			return tk.TokError, nil, literalError(orig, t, err)
+		}
+
 		switch t {
 		case tk.TokI8:
-			return t, int8(valSigned), nil
+			return t, int8(val), nil
 		case tk.TokI16:
-			return t, int16(valSigned), nil
+			return t, int16(val), nil
 		case tk.TokI32:
-			return t, int32(valSigned), nil
-		case tk.TokI64:
-			return t, int64(valSigned), nil
+			return t, int32(val), nil
+		default:
+			return t, val, nil
+		}
+
+	default:
+		val, err := strconv.ParseUint(numPart, base, literalBits(t))
+		if err != nil {
+			return tk.TokError, nil, literalError(orig, t, err)
 		}
-	}
 
-	valUnsigned, errU := strconv.ParseUint(numPart, base, 64)
-	if errU == nil {
 		switch t {
 		case tk.TokU8:
-			return t, uint8(valUnsigned), nil
+			return t, uint8(val), nil
 		case tk.TokU16:
-			return t, uint16(valUnsigned), nil
+			return t, uint16(val), nil
 		case tk.TokU32:
-			return t, uint32(valUnsigned), nil
-		case tk.TokU64:
-			return t, uint64(valUnsigned), nil
+			return t, uint32(val), nil
+		default:
+			return t, val, nil
 		}
 	}
+}
 
-	return tk.TokError, nil, fmt.Errorf("overflow or invalid literal %q", orig)
+// Reports whether the underscore at lit[i] sits between two digits, or right after a base prefix
+func separatorAllowed(lit string, i int, state string, base int, digitOK func(byte) bool) bool {
+	isDigit := digitOK
+	switch state {
+	case "INT", "FRAC":
+	case "EXP":
+		isDigit = func(r byte) bool { return r >= '0' && r <= '9' }
+	default:
+		return false
+	}
+
+	prevOK := (i == 0 && base != 10) || (i > 0 && isDigit(lit[i-1]))
+	nextOK := i+1 < len(lit) && isDigit(lit[i+1])
+
+	return prevOK && nextOK
+}
+
+// Returns the letters that start an exponent in the given base, a power of 10 in
+// decimal and a power of 2 in hexadecimal
+func exponentMarks(base int) string {
+	switch base {
+	case 10:
+		return "eE"
+	case 16:
+		return "pP"
+	default:
+		return ""
+	}
+}
+
+func isExponentMark(c byte, base int) bool {
+	return strings.IndexByte(exponentMarks(base), c) != -1
+}
+
+func literalBits(t tk.TokenType) int {
+	switch t {
+	case tk.TokI8, tk.TokU8:
+		return 8
+	case tk.TokI16, tk.TokU16:
+		return 16
+	case tk.TokI32, tk.TokU32, tk.TokF32:
+		return 32
+	default:
+		return 64
+	}
+}
+
+func literalError(orig string, t tk.TokenType, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return &LiteralOverflowError{Literal: orig, Type: literalTypeNames[t]}
+	}
+	return fmt.Errorf("invalid number literal %q", orig)
 }
@@ -0,0 +1,248 @@
+package lexer
+
+import (
+	"fracta/internal/diag"
+	tok "fracta/internal/token"
+	"strings"
+	"unicode/utf8"
+)
+
+// Scans a string literal, the opening quote being already consumed
+func (l *Lexer) scanStringLiteral(t *tok.Token) {
+	if l.peek() == '"' && l.peekAt(1) == '"' {
+		_, _ = l.advance(), l.advance()
+		l.scanMultilineString(t)
+		return
+	}
+
+	var sb strings.Builder
+	bodyStart := l.pos
+
+	for {
+		// A newline ends the literal, lexing then resumes on the next line
+		r := l.peek()
+		if r == 0 || r == '\n' {
+			t.Kind = tok.TokError
+			t.Lexeme = `"` + sb.String()
+			l.addError(diag.ErrUnterminatedString, "unterminated string literal")
+			return
+		}
+		_ = l.advance()
+
+		if r == '"' {
+			break
+		}
+
+		sb.WriteRune(r)
+
+		if r == '\\' && l.peek() != '\n' {
+			if r2 := l.advance(); r2 != 0 {
+				sb.WriteRune(r2)
+			}
+		}
+	}
+
+	t.Lexeme = `"` + sb.String() + `"`
+
+	val, ok := l.decodeEscapes(sb.String(), bodyStart, false)
+	if !ok {
+		t.Kind = tok.TokError
+		return
+	}
+
+	t.Kind = tok.TokString
+	t.Value = val
+}
+
+// Scans a backtick-delimited raw string, which may span several lines and has no escapes.
+// Carriage returns are dropped from the value, so it does not depend on line endings.
+func (l *Lexer) scanRawString(t *tok.Token) {
+	var exact, value strings.Builder
+	exact.WriteRune('`')
+
+	for {
+		r := l.advance()
+		if r == 0 {
+			t.Kind = tok.TokError
+			t.Lexeme = exact.String()
+			l.addError(diag.ErrUnterminatedString, "unterminated raw string literal")
+			return
+		}
+
+		exact.WriteRune(r)
+
+		if r == '`' {
+			break
+		}
+		if r != '\r' {
+			value.WriteRune(r)
+		}
+	}
+
+	t.Kind = tok.TokString
+	t.Value = value.String()
+	t.Lexeme = exact.String()
+}
+
+type multilineStringLine struct {
+	text  string
+	start tok.Position
+}
+
+// Scans a '"""' string, the opening delimiter being already consumed.
+// Content starts on the line after the opening delimiter, and the closing one must be
+// alone on its line. Its indentation is stripped from every line of the content.
+func (l *Lexer) scanMultilineString(t *tok.Token) {
+	var exact strings.Builder
+	exact.WriteString(`"""`)
+
+	next := func() rune {
+		r := l.advance()
+		if r != 0 {
+			exact.WriteRune(r)
+		}
+		return r
+	}
+
+	ok := true
+
+	for r := l.peek(); r == ' ' || r == '\t' || r == '\r'; r = l.peek() {
+		_ = next()
+	}
+	if r := l.peek(); r != '\n' && r != 0 {
+		start := l.pos
+		for r := l.peek(); r != 0 && r != '\n'; r = l.peek() {
+			_ = next()
+		}
+		l.addErrorSpan(diag.ErrInvalidMultilineString, l.spanFrom(start), "multi-line string content must start on the line after the opening '\"\"\"'")
+		ok = false
+	}
+
+	var lines []multilineStringLine
+	var indent string
+
+	for {
+		if next() == 0 {
+			t.Kind = tok.TokError
+			t.Lexeme = exact.String()
+			l.addError(diag.ErrUnterminatedString, "unterminated multi-line string literal")
+			return
+		}
+
+		var sb strings.Builder
+		start := l.pos
+
+		for r := l.peek(); r == ' ' || r == '\t'; r = l.peek() {
+			sb.WriteRune(next())
+		}
+
+		if l.peek() == '"' && l.peekAt(1) == '"' && l.peekAt(2) == '"' {
+			_, _, _ = next(), next(), next()
+			indent = sb.String()
+			break
+		}
+
+		for r := l.peek(); r != 0 && r != '\n'; r = l.peek() {
+			sb.WriteRune(next())
+		}
+
+		lines = append(lines, multilineStringLine{text: strings.TrimSuffix(sb.String(), "\r"), start: start})
+	}
+
+	t.Lexeme = exact.String()
+
+	content := make([]string, 0, len(lines))
+
+	for _, v := range lines {
+		if strings.TrimLeft(v.text, " \t") == "" {
+			content = append(content, "")
+			continue
+		}
+
+		if !strings.HasPrefix(v.text, indent) {
+			n := len(v.text) - len(strings.TrimLeft(v.text, " \t"))
+			span := tok.Span{File: l.filename, Start: v.start, End: v.start}
+			span.End.Offset += n
+			span.End.Column += n
+			l.addErrorSpan(diag.ErrInvalidMultilineString, span, "line is indented less than the closing '\"\"\"'")
+			ok = false
+			continue
+		}
+
+		start := v.start
+		start.Offset += len(indent)
+		start.Column += len(indent)
+
+		line, lineOk := l.decodeEscapes(v.text[len(indent):], start, false)
+		ok = ok && lineOk
+		content = append(content, line)
+	}
+
+	if !ok {
+		t.Kind = tok.TokError
+		return
+	}
+
+	t.Kind = tok.TokString
+	t.Value = strings.Join(content, "\n")
+}
+
+// Scans a character literal, the opening quote being already consumed
+func (l *Lexer) scanCharLiteral(t *tok.Token) {
+	var sb strings.Builder
+	sb.WriteRune('\'')
+	bodyStart := l.pos
+
+	for {
+		r := l.peek()
+		if r == 0 || r == '\n' {
+			t.Kind = tok.TokError
+			t.Lexeme = sb.String()
+			l.addError(diag.ErrUnterminatedChar, "unterminated character literal")
+			return
+		}
+		_ = l.advance()
+
+		sb.WriteRune(r)
+
+		if r == '\'' {
+			break
+		}
+
+		if r == '\\' {
+			r2 := l.peek()
+			if r2 == 0 || r2 == '\n' {
+				t.Kind = tok.TokError
+				t.Lexeme = sb.String()
+				l.addError(diag.ErrUnterminatedChar, "unterminated escape sequence in character literal")
+				return
+			}
+			_ = l.advance()
+			sb.WriteRune(r2)
+		}
+	}
+
+	raw := sb.String()
+	t.Lexeme = raw
+	t.Kind = tok.TokError
+
+	content := raw[1 : len(raw)-1]
+
+	if content == "" {
+		l.addError(diag.ErrInvalidChar, "empty character literal")
+		return
+	}
+
+	value, ok := l.decodeEscapes(content, bodyStart, true)
+	if !ok {
+		return
+	}
+	if utf8.RuneCountInString(value) != 1 {
+		l.addError(diag.ErrInvalidChar, "character literal must contain exactly one character")
+		return
+	}
+
+	r, _ := utf8.DecodeRuneInString(value)
+	t.Kind = tok.TokChar
+	t.Value = r
+}
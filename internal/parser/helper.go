@@ -11,29 +11,70 @@ func (p *Parser) isAtEnd() bool {
 	return p.peek().Kind == token.TokEndOfFile
 }
 
+// Makes sure at least n tokens are buffered
+func (p *Parser) fill(n int) {
+	afterError := false
+
+	for len(p.lookahead) < n {
+		t := p.src.GetToken()
+
+		// Error tokens were already reported by the lexer, the parser only sees the valid ones
+		if t.Kind == token.TokError {
+			afterError = true
+			continue
+		}
+
+		p.lookahead = append(p.lookahead, bufferedToken{tok: t, afterLexError: afterError})
+		afterError = false
+	}
+}
+
 func (p *Parser) peek() *token.Token {
-	return &p.toks[p.current]
+	return p.peekAt(0)
+}
+
+// Returns the n-th token after the current one, without consuming anything
+func (p *Parser) peekAt(n int) *token.Token {
+	p.fill(n + 1)
+	return &p.lookahead[n].tok
 }
 
 func (p *Parser) previous() *token.Token {
-	if p.current <= 0 {
-		return nil
-	}
-	return &p.toks[p.current-1]
+	return p.prev
 }
 
 func (p *Parser) advance() *token.Token {
 	if !p.isAtEnd() {
-		p.current++
+		cur := p.lookahead[0]
+		if cur.afterLexError {
+			p.stmtHasLexError = true
+		}
+
+		p.prev = &cur.tok
+		p.lookahead = p.lookahead[1:]
 	}
 	return p.previous()
 }
 
+// Tokens that can be inserted by a suggested fix when missing
+var insertableTokens = map[token.TokenType]string{
+	token.TokSemicolon:    ";",
+	token.TokCloseParen:   ")",
+	token.TokCloseSquare:  "]",
+	token.TokCloseBracket: "}",
+}
+
 func (p *Parser) consume(tt token.TokenType, f string, v ...any) (*token.Token, error) {
 	if p.check(tt) {
 		return p.advance(), nil
 	}
-	err := p.addError(f, v...)
+	err := p.addError(diag.ErrExpectedToken, f, v...)
+
+	if text, ok := insertableTokens[tt]; ok && p.previous() != nil {
+		at := p.previous().Span
+		at.Start = at.End
+		err.WithFix(at, text, "insert '%s'", text)
+	}
 	return nil, err
 }
 
@@ -53,16 +94,25 @@ func (p *Parser) match(tts ...token.TokenType) bool {
 	return false
 }
 
-func (p *Parser) addError(f string, v ...any) *diag.ErrorContainer {
-	line := 0
+// Returns the span going from start up to the end of the last consumed token
+func (p *Parser) spanFrom(start token.Span) token.Span {
+	return start.To(p.previous().Span)
+}
+
+func (p *Parser) addError(code diag.Code, f string, v ...any) *diag.ErrorContainer {
+	span := token.Span{File: p.filename}
 	if prev := p.previous(); prev != nil {
-		line = prev.Line
+		span = prev.Span
 	}
 
 	msg := fmt.Sprintf(f, v...)
-	o := diag.CreateError(msg, p.filename, line)
+	o := diag.CreateError(code, msg, span)
 
-	p.errors = append(p.errors, o)
+	// Syntax errors right after a lexing error are most likely caused by it
+	p.fill(1)
+	if !p.stmtHasLexError && !p.lookahead[0].afterLexError {
+		p.errors = append(p.errors, o)
+	}
 	return o
 }
 
@@ -5,6 +5,7 @@ import (
 	"fracta/internal/ast"
 	"fracta/internal/diag"
 	"fracta/internal/token"
+	"fracta/internal/types"
 )
 
 func (p *Parser) Parse() (*ast.FileSourceNode, error) {
@@ -37,37 +38,72 @@ func (p *Parser) Parse() (*ast.FileSourceNode, error) {
 func (p *Parser) typeExpr() (ast.Type, error) {
 	switch {
 	case p.match(token.TokIdentifier):
-		id := p.previous()
-		btype, ok := ast.BuiltinTypeNameMap[id.Identifier]
+		return &ast.TypeName{Name: *p.previous()}, nil
 
-		if !ok {
-			return p.namedType()
-		} else {
-			return btype, nil
+	case p.match(token.TokOpStar):
+		star := *p.previous()
+		elem, err := p.typeExpr()
+		if err != nil {
+			return nil, err
 		}
+		return &ast.PointerType{Star: star, Elem: elem}, nil
+
+	case p.match(token.TokOpenSquare):
+		open := *p.previous()
+
+		var length ast.Expression
+		if !p.check(token.TokCloseSquare) {
+			var err error
+			length, err = p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		_, err := p.consume(token.TokCloseSquare, "expected ']'")
+		if err != nil {
+			return nil, err
+		}
+
+		elem, err := p.typeExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		if length == nil {
+			return &ast.SliceType{Open: open, Elem: elem}, nil
+		}
+		return &ast.ArrayType{Open: open, Len: length, Elem: elem}, nil
 
 	default:
-		err := p.addError("invalid type expression")
+		err := p.addError(diag.ErrInvalidTypeExpr, "invalid type expression")
 		return nil, err
 	}
 }
 
-func (p *Parser) namedType() (ast.Type, error) {
-	name := p.previous()
-	return &ast.NamedType{
-		Name: *name,
-	}, nil
-}
-
 func (p *Parser) statement() (ast.Statement, error) {
 	var stmt ast.Statement
 	var err error
 
+	p.stmtHasLexError = false
+
 	switch {
 	case p.match(token.TokKwFunc):
 		stmt, err = p.funcDeclStmt()
 	case p.match(token.TokKwReturn):
 		stmt, err = p.returnStmt()
+	case p.match(token.TokKwVar, token.TokKwLet):
+		stmt, err = p.varDeclStmt()
+	case p.match(token.TokOpenBracket):
+		stmt, err = p.blockStmt()
+	case p.match(token.TokKwIf):
+		stmt, err = p.ifStmt()
+	case p.check(token.TokIdentifier) && p.peekAt(1).Kind == token.TokOpColon:
+		stmt, err = p.labeledStmt()
+	case p.match(token.TokKwWhile, token.TokKwFor, token.TokKwLoop):
+		stmt, err = p.loopStmt(nil, p.previous().Span)
+	case p.match(token.TokKwBreak, token.TokKwContinue):
+		stmt, err = p.jumpStmt()
 	default:
 		stmt, err = p.exprStmt()
 	}
@@ -80,7 +116,8 @@ func (p *Parser) statement() (ast.Statement, error) {
 }
 
 func (p *Parser) funcDeclStmt() (ast.Statement, error) {
-	line := p.previous().Line
+	start := p.previous().Span
+	doc := p.previous().Doc
 	name, err := p.consume(token.TokIdentifier, "expected identifier")
 
 	if err != nil {
@@ -125,7 +162,7 @@ func (p *Parser) funcDeclStmt() (ast.Statement, error) {
 
 	var rtp ast.Type
 
-	if !p.check(token.TokOpenBracket) {
+	if !p.check(token.TokOpenBracket, token.TokSemicolon) {
 		rtp, err = p.typeExpr()
 
 		if err != nil {
@@ -143,22 +180,252 @@ func (p *Parser) funcDeclStmt() (ast.Statement, error) {
 	} else if p.match(token.TokSemicolon) {
 		body = nil
 	} else {
-		err = p.addError("unexpected token")
+		err = p.addError(diag.ErrExpectedToken, "expected function body or ';'")
 		return nil, err
 	}
 
 	return &ast.FunctionDeclaration{
-		StmtBase:   ast.StmtBase{Line: line},
+		StmtBase:   ast.StmtBase{Span: p.spanFrom(start)},
+		Doc:        doc,
 		Name:       *name,
 		Args:       args,
 		ReturnType: rtp,
 		Body:       body,
 	}, nil
+}
+
+func (p *Parser) varDeclStmt() (ast.Statement, error) {
+	start := p.previous().Span
+	doc := p.previous().Doc
+	mutable := p.previous().Kind == token.TokKwVar
+
+	name, err := p.consume(token.TokIdentifier, "expected variable name")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var vtype ast.Type
+
+	if !p.check(token.TokOpAssign, token.TokSemicolon) {
+		vtype, err = p.typeExpr()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var value ast.Expression
+
+	if p.match(token.TokOpAssign) {
+		value, err = p.parseExpression(0)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.consume(token.TokSemicolon, "expected ';'")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.VariableDeclaration{
+		StmtBase: ast.StmtBase{Span: p.spanFrom(start)},
+		Doc:      doc,
+		Mutable:  mutable,
+		Name:     *name,
+		Type:     vtype,
+		Value:    value,
+	}, nil
+}
+
+func (p *Parser) ifStmt() (ast.Statement, error) {
+	start := p.previous().Span
+
+	cond, err := p.parseExpression(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(token.TokOpenBracket, "expected '{' after the condition")
+
+	if err != nil {
+		return nil, err
+	}
+
+	then, err := p.blockStmt()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var otherwise ast.Statement
+
+	if p.match(token.TokKwElse) {
+		switch {
+		case p.match(token.TokKwIf):
+			otherwise, err = p.ifStmt()
+		case p.match(token.TokOpenBracket):
+			otherwise, err = p.blockStmt()
+		default:
+			err = p.addError(diag.ErrExpectedToken, "expected '{' or 'if' after 'else'")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ast.IfStatement{
+		StmtBase:  ast.StmtBase{Span: p.spanFrom(start)},
+		Condition: cond,
+		Then:      then.(*ast.BlockStatement),
+		Else:      otherwise,
+	}, nil
+}
+
+// Parses 'name: loop', only loops can be labeled
+func (p *Parser) labeledStmt() (ast.Statement, error) {
+	label := p.advance()
+	start := label.Span
+	p.advance() // ':'
+
+	if !p.match(token.TokKwWhile, token.TokKwFor, token.TokKwLoop) {
+		err := p.addError(diag.ErrExpectedToken, "expected 'while', 'for' or 'loop' after label %s", label.Identifier)
+		return nil, err
+	}
+
+	return p.loopStmt(label, start)
+}
+
+// Parses the loop started by the previous keyword
+func (p *Parser) loopStmt(label *token.Token, start token.Span) (ast.Statement, error) {
+	var stmt ast.Statement
+	var err error
+
+	switch p.previous().Kind {
+	case token.TokKwWhile:
+		stmt, err = p.whileStmt(label)
+	case token.TokKwFor:
+		stmt, err = p.forStmt(label)
+	default:
+		stmt, err = p.loopBody(func(body *ast.BlockStatement) ast.Statement {
+			return &ast.LoopStatement{Label: label, Body: body}
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	stmt.StmtNode().Span = p.spanFrom(start)
+	return stmt, nil
+}
+
+// Parses the block of a loop and builds the loop around it
+func (p *Parser) loopBody(build func(body *ast.BlockStatement) ast.Statement) (ast.Statement, error) {
+	_, err := p.consume(token.TokOpenBracket, "expected '{' to start the loop body")
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.blockStmt()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return build(body.(*ast.BlockStatement)), nil
+}
+
+func (p *Parser) whileStmt(label *token.Token) (ast.Statement, error) {
+	cond, err := p.parseExpression(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p.loopBody(func(body *ast.BlockStatement) ast.Statement {
+		return &ast.WhileStatement{Label: label, Condition: cond, Body: body}
+	})
+}
 
+func (p *Parser) forStmt(label *token.Token) (ast.Statement, error) {
+	var init ast.Statement
+	var cond, post ast.Expression
+	var err error
+
+	// The init statement consumes its own ';'
+	switch {
+	case p.match(token.TokSemicolon):
+	case p.match(token.TokKwVar, token.TokKwLet):
+		init, err = p.varDeclStmt()
+	default:
+		init, err = p.exprStmt()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.check(token.TokSemicolon) {
+		cond, err = p.parseExpression(0)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.consume(token.TokSemicolon, "expected ';' after the loop condition")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.check(token.TokOpenBracket) {
+		post, err = p.parseExpression(0)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p.loopBody(func(body *ast.BlockStatement) ast.Statement {
+		return &ast.ForStatement{Label: label, Init: init, Condition: cond, Post: post, Body: body}
+	})
+}
+
+// Parses 'break' and 'continue', with an optional label
+func (p *Parser) jumpStmt() (ast.Statement, error) {
+	kw := p.previous()
+	start := kw.Span
+	isBreak := kw.Kind == token.TokKwBreak
+
+	var label *token.Token
+
+	if p.match(token.TokIdentifier) {
+		label = p.previous()
+	}
+
+	_, err := p.consume(token.TokSemicolon, "expected ';'")
+
+	if err != nil {
+		return nil, err
+	}
+
+	base := ast.StmtBase{Span: p.spanFrom(start)}
+	if isBreak {
+		return &ast.BreakStatement{StmtBase: base, Label: label}, nil
+	}
+	return &ast.ContinueStatement{StmtBase: base, Label: label}, nil
 }
 
 func (p *Parser) returnStmt() (ast.Statement, error) {
-	line := p.previous().Line
+	start := p.previous().Span
 	var value ast.Expression
 	var err error
 
@@ -179,13 +446,13 @@ func (p *Parser) returnStmt() (ast.Statement, error) {
 	}
 
 	return &ast.ReturnStatement{
-		StmtBase: ast.StmtBase{Line: line},
+		StmtBase: ast.StmtBase{Span: p.spanFrom(start)},
 		Value:    value,
 	}, nil
 }
 
 func (p *Parser) blockStmt() (ast.Statement, error) {
-	line := p.previous().Line
+	start := p.previous().Span
 	body := make([]ast.Statement, 0)
 
 	for !p.check(token.TokCloseBracket) && !p.isAtEnd() {
@@ -208,7 +475,7 @@ func (p *Parser) blockStmt() (ast.Statement, error) {
 	}
 
 	return &ast.BlockStatement{
-		StmtBase: ast.StmtBase{Line: line},
+		StmtBase: ast.StmtBase{Span: p.spanFrom(start)},
 		Body:     body,
 	}, nil
 }
@@ -227,7 +494,7 @@ func (p *Parser) exprStmt() (ast.Statement, error) {
 	}
 
 	return &ast.ExpressionStatement{
-		StmtBase:   ast.StmtBase{Line: expr.ExprNode().Line},
+		StmtBase:   ast.StmtBase{Span: p.spanFrom(expr.ExprNode().Span)},
 		Expression: expr,
 	}, nil
 }
@@ -238,7 +505,7 @@ func (p *Parser) parseExpression(minBp int) (ast.Expression, error) {
 	prefix, ok := p.prefixParsers[tok.Kind]
 
 	if !ok {
-		err := p.addError("invalid token in expression: %v", *tok)
+		err := p.addError(diag.ErrInvalidExprToken, "invalid token in expression: %v", *tok)
 		return nil, err
 	}
 
@@ -279,7 +546,7 @@ func (p *Parser) parseExpression(minBp int) (ast.Expression, error) {
 		break
 	}
 
-	left.ExprNode().Type = ast.UnkownType{}
+	left.ExprNode().Type = types.Invalid
 
 	return left, nil
 }
@@ -5,32 +5,47 @@ import (
 	"fracta/internal/token"
 )
 
+// Creates a parser over an already lexed token slice
 func NewParser(toks []token.Token, filename string) *Parser {
+	return NewParserFromSource(SliceSource(toks), filename)
+}
+
+// Creates a parser reading tokens on demand, only a few tokens of lookahead are kept in memory
+func NewParserFromSource(src TokenSource, filename string) *Parser {
 	parser := Parser{}
 	parser.filename = filename
-	parser.toks = toks
+	parser.src = src
 	parser.errors = make([]*diag.ErrorContainer, 0)
 
 	parser.prefixParsers = map[token.TokenType]prefixParser{
-		token.TokI8:     &LiteralParser{},
-		token.TokI16:    &LiteralParser{},
-		token.TokI32:    &LiteralParser{},
-		token.TokI64:    &LiteralParser{},
-		token.TokU8:     &LiteralParser{},
-		token.TokU16:    &LiteralParser{},
-		token.TokU32:    &LiteralParser{},
-		token.TokU64:    &LiteralParser{},
-		token.TokF32:    &LiteralParser{},
-		token.TokF64:    &LiteralParser{},
-		token.TokString: &LiteralParser{},
-		token.TokChar:   &LiteralParser{},
+		token.TokI8:      &LiteralParser{},
+		token.TokI16:     &LiteralParser{},
+		token.TokI32:     &LiteralParser{},
+		token.TokI64:     &LiteralParser{},
+		token.TokU8:      &LiteralParser{},
+		token.TokU16:     &LiteralParser{},
+		token.TokU32:     &LiteralParser{},
+		token.TokU64:     &LiteralParser{},
+		token.TokF32:     &LiteralParser{},
+		token.TokF64:     &LiteralParser{},
+		token.TokInt:     &LiteralParser{},
+		token.TokFloat:   &LiteralParser{},
+		token.TokString:  &LiteralParser{},
+		token.TokChar:    &LiteralParser{},
+		token.TokKwTrue:  &LiteralParser{},
+		token.TokKwFalse: &LiteralParser{},
+		token.TokKwNull:  &LiteralParser{},
 
 		token.TokIdentifier: &IdentifierParser{},
 		token.TokOpenParen:  &GroupingParser{},
+		token.TokOpenSquare: &ArrayLiteralParser{},
 
-		token.TokOpPlus:  &PrefixOperatorParser{rbp: 30},
-		token.TokOpMinus: &PrefixOperatorParser{rbp: 30},
-		token.TokOpStar:  &PrefixOperatorParser{rbp: 40},
+		token.TokOpPlus:      &PrefixOperatorParser{rbp: 30},
+		token.TokOpMinus:     &PrefixOperatorParser{rbp: 30},
+		token.TokOpStar:      &PrefixOperatorParser{rbp: 40},
+		token.TokOpAmpersand: &PrefixOperatorParser{rbp: 40},
+		token.TokOpBang:      &PrefixOperatorParser{rbp: 30},
+		token.TokOpTilde:     &PrefixOperatorParser{rbp: 30},
 	}
 
 	parser.infixParsers = map[token.TokenType]infixParser{
@@ -39,6 +54,36 @@ func NewParser(toks []token.Token, filename string) *Parser {
 		token.TokOpStar:  &BinaryOperatorParser{precedence: 20, assoc: AssocLeft},
 		token.TokOpSlash: &BinaryOperatorParser{precedence: 20, assoc: AssocLeft},
 		token.TokOpMod:   &BinaryOperatorParser{precedence: 20, assoc: AssocLeft},
+
+		token.TokOpAssign:           &AssignmentParser{},
+		token.TokOpPlusAssign:       &AssignmentParser{},
+		token.TokOpMinusAssign:      &AssignmentParser{},
+		token.TokOpStarAssign:       &AssignmentParser{},
+		token.TokOpSlashAssign:      &AssignmentParser{},
+		token.TokOpModAssign:        &AssignmentParser{},
+		token.TokOpAmpersandAssign:  &AssignmentParser{},
+		token.TokOpPipeAssign:       &AssignmentParser{},
+		token.TokOpCaretAssign:      &AssignmentParser{},
+		token.TokOpShiftLeftAssign:  &AssignmentParser{},
+		token.TokOpShiftRightAssign: &AssignmentParser{},
+
+		// Unlike in C, bitwise operators bind tighter than comparisons, so 'x & mask == 0' works
+		token.TokOpLogicalOr:  &BinaryOperatorParser{precedence: 1, assoc: AssocLeft},
+		token.TokOpLogicalAnd: &BinaryOperatorParser{precedence: 2, assoc: AssocLeft},
+		token.TokOpPipe:       &BinaryOperatorParser{precedence: 6, assoc: AssocLeft},
+		token.TokOpCaret:      &BinaryOperatorParser{precedence: 7, assoc: AssocLeft},
+		token.TokOpAmpersand:  &BinaryOperatorParser{precedence: 8, assoc: AssocLeft},
+		token.TokOpShiftLeft:  &BinaryOperatorParser{precedence: 9, assoc: AssocLeft},
+		token.TokOpShiftRight: &BinaryOperatorParser{precedence: 9, assoc: AssocLeft},
+
+		token.TokOpEq:           &BinaryOperatorParser{precedence: 5, assoc: AssocLeft},
+		token.TokOpNotEq:        &BinaryOperatorParser{precedence: 5, assoc: AssocLeft},
+		token.TokOpLessThan:     &BinaryOperatorParser{precedence: 5, assoc: AssocLeft},
+		token.TokOpGreaterThan:  &BinaryOperatorParser{precedence: 5, assoc: AssocLeft},
+		token.TokOpLessEqual:    &BinaryOperatorParser{precedence: 5, assoc: AssocLeft},
+		token.TokOpGreaterEqual: &BinaryOperatorParser{precedence: 5, assoc: AssocLeft},
+
+		token.TokKwAs: &CastParser{precedence: 25},
 	}
 
 	parser.postfixParsers = map[token.TokenType]postfixParser{
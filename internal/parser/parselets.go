@@ -9,7 +9,7 @@ type LiteralParser struct{}
 
 func (*LiteralParser) Parse(p *Parser, tok token.Token) (ast.Expression, error) {
 	return &ast.Literal{
-		ExprBase: ast.ExprBase{Line: tok.Line},
+		ExprBase: ast.ExprBase{Span: tok.Span},
 		Value:    tok,
 	}, nil
 }
@@ -18,11 +18,46 @@ func (*LiteralParser) Precedence() int {
 	return 0
 }
 
+type ArrayLiteralParser struct{}
+
+func (*ArrayLiteralParser) Parse(p *Parser, tok token.Token) (ast.Expression, error) {
+	elems := make([]ast.Expression, 0)
+
+	if !p.check(token.TokCloseSquare) {
+		expr, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, expr)
+
+		for p.match(token.TokOpComma) {
+			expr, err = p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, expr)
+		}
+	}
+
+	closing, err := p.consume(token.TokCloseSquare, "expected ']' after the array elements")
+	if err != nil {
+		return nil, err
+	}
+	return &ast.ArrayLiteral{
+		ExprBase: ast.ExprBase{Span: tok.Span.To(closing.Span)},
+		Elems:    elems,
+	}, nil
+}
+
+func (*ArrayLiteralParser) Precedence() int {
+	return 0
+}
+
 type IdentifierParser struct{}
 
 func (*IdentifierParser) Parse(p *Parser, tok token.Token) (ast.Expression, error) {
 	return &ast.Identifier{
-		ExprBase: ast.ExprBase{Line: tok.Line},
+		ExprBase: ast.ExprBase{Span: tok.Span},
 		Ident:    tok,
 	}, nil
 }
@@ -61,7 +96,7 @@ func (o *PrefixOperatorParser) Parse(p *Parser, tok token.Token) (ast.Expression
 	}
 
 	return &ast.Unary{
-		ExprBase: ast.ExprBase{Line: tok.Line},
+		ExprBase: ast.ExprBase{Span: tok.Span.To(right.ExprNode().Span)},
 		Op:       tok,
 		SubExpr:  right,
 	}, nil
@@ -97,7 +132,7 @@ func (o *BinaryOperatorParser) Parse(p *Parser, left ast.Expression, tok token.T
 	}
 
 	return &ast.Binary{
-		ExprBase: ast.ExprBase{Line: tok.Line},
+		ExprBase: ast.ExprBase{Span: left.ExprNode().Span.To(right.ExprNode().Span)},
 		Op:       tok,
 		Left:     left,
 		Right:    right,
@@ -108,13 +143,59 @@ func (o *BinaryOperatorParser) Lbp() int {
 	return o.precedence
 }
 
+// Assignments are right-associative and bind the loosest, so 'a = b = c + 1' assigns c + 1 to both
+type AssignmentParser struct{}
+
+func (o *AssignmentParser) Parse(p *Parser, left ast.Expression, tok token.Token) (ast.Expression, error) {
+	right, err := p.parseExpression(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.Assignment{
+		ExprBase: ast.ExprBase{Span: left.ExprNode().Span.To(right.ExprNode().Span)},
+		Op:       tok,
+		Target:   left,
+		Value:    right,
+	}, nil
+}
+
+func (o *AssignmentParser) Lbp() int {
+	return 0
+}
+
+// Parses 'value as T'. It binds tighter than binary operators, but looser than prefix ones:
+// '-x as i64' converts -x, 'a * b as i64' only converts b.
+type CastParser struct {
+	precedence int
+}
+
+func (c *CastParser) Parse(p *Parser, left ast.Expression, tok token.Token) (ast.Expression, error) {
+	target, err := p.typeExpr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.Cast{
+		ExprBase: ast.ExprBase{Span: p.spanFrom(left.ExprNode().Span)},
+		Value:    left,
+		Target:   target,
+	}, nil
+}
+
+func (c *CastParser) Lbp() int {
+	return c.precedence
+}
+
 type PostfixOperatorParser struct {
 	precedence int
 }
 
 func (o *PostfixOperatorParser) Parse(p *Parser, left ast.Expression, tok token.Token) (ast.Expression, error) {
 	return &ast.Unary{
-		ExprBase: ast.ExprBase{Line: tok.Line},
+		ExprBase: ast.ExprBase{Span: left.ExprNode().Span.To(tok.Span)},
 		Op:       tok,
 		SubExpr:  left,
 	}, nil
@@ -151,12 +232,12 @@ func (c *CallParser) Parse(p *Parser, left ast.Expression, tok token.Token) (ast
 			args = append(args, expr)
 		}
 	}
-	_, err := p.consume(token.TokCloseParen, "expected ')'")
+	closing, err := p.consume(token.TokCloseParen, "expected ')'")
 	if err != nil {
 		return nil, err
 	}
 	return &ast.Call{
-		ExprBase: ast.ExprBase{Line: tok.Line},
+		ExprBase: ast.ExprBase{Span: left.ExprNode().Span.To(closing.Span)},
 		Callee:   left,
 		Args:     args,
 	}, nil
@@ -174,9 +255,18 @@ func (c *IndexParser) Parse(p *Parser, left ast.Expression, tok token.Token) (as
 	args := make([]ast.Expression, 0)
 
 	if !p.check(token.TokCloseSquare) {
-		expr, err := p.parseExpression(0)
-		if err != nil {
-			return nil, err
+		var expr ast.Expression
+		var err error
+
+		if !p.check(token.TokOpColon) {
+			expr, err = p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if p.match(token.TokOpColon) {
+			return c.parseSlice(p, left, expr)
 		}
 		args = append(args, expr)
 
@@ -193,17 +283,41 @@ func (c *IndexParser) Parse(p *Parser, left ast.Expression, tok token.Token) (as
 			args = append(args, expr)
 		}
 	}
-	_, err := p.consume(token.TokCloseSquare, "expected ']'")
+	closing, err := p.consume(token.TokCloseSquare, "expected ']'")
 	if err != nil {
 		return nil, err
 	}
 	return &ast.Indexed{
-		ExprBase: ast.ExprBase{Line: tok.Line},
+		ExprBase: ast.ExprBase{Span: left.ExprNode().Span.To(closing.Span)},
 		Indexee:  left,
 		Indices:  args,
 	}, nil
 }
 
+// Parses the rest of 'value[low:high]', after the colon
+func (c *IndexParser) parseSlice(p *Parser, left ast.Expression, low ast.Expression) (ast.Expression, error) {
+	var high ast.Expression
+	var err error
+
+	if !p.check(token.TokCloseSquare) {
+		high, err = p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	closing, err := p.consume(token.TokCloseSquare, "expected ']'")
+	if err != nil {
+		return nil, err
+	}
+	return &ast.Sliced{
+		ExprBase: ast.ExprBase{Span: left.ExprNode().Span.To(closing.Span)},
+		Value:    left,
+		Low:      low,
+		High:     high,
+	}, nil
+}
+
 func (c *IndexParser) Precedence() int {
 	return c.precedence
 }
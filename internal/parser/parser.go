@@ -7,10 +7,14 @@ import (
 )
 
 type Parser struct {
-	toks     []token.Token
-	current  int
+	src      TokenSource
 	filename string
 
+	lookahead []bufferedToken // Tokens read from src but not consumed yet, the first one is the current token
+	prev      *token.Token    // Last consumed token, if any
+
+	stmtHasLexError bool
+
 	prefixParsers  map[token.TokenType]prefixParser
 	infixParsers   map[token.TokenType]infixParser
 	postfixParsers map[token.TokenType]postfixParser
@@ -19,6 +23,11 @@ type Parser struct {
 	done   bool
 }
 
+type bufferedToken struct {
+	tok           token.Token
+	afterLexError bool // Set when a lexing error was dropped right before this token
+}
+
 type prefixParser interface {
 	Parse(*Parser, token.Token) (ast.Expression, error)
 	Precedence() int
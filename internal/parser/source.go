@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"fracta/internal/token"
+	"iter"
+)
+
+// Provides tokens to the parser one at a time.
+// Once the end of file is reached, every call must keep returning an end of file token.
+type TokenSource interface {
+	GetToken() token.Token
+}
+
+type sliceSource struct {
+	toks    []token.Token
+	current int
+}
+
+// Returns a source reading from an already lexed token slice
+func SliceSource(toks []token.Token) TokenSource {
+	return &sliceSource{toks: toks}
+}
+
+func (s *sliceSource) GetToken() token.Token {
+	if s.current >= len(s.toks) {
+		return endOfFileAfter(s.toks)
+	}
+
+	t := s.toks[s.current]
+	s.current++
+	return t
+}
+
+func endOfFileAfter(toks []token.Token) token.Token {
+	out := token.Token{Kind: token.TokEndOfFile}
+	if len(toks) != 0 {
+		out.Span = toks[len(toks)-1].Span
+		out.Span.Start = out.Span.End
+	}
+	return out
+}
+
+type seqSource struct {
+	next func() (token.Token, bool)
+	stop func()
+	last token.Token
+}
+
+// Returns a source pulling tokens from an iterator, which is stopped once it yields the end of file
+func SeqSource(seq iter.Seq[token.Token]) TokenSource {
+	next, stop := iter.Pull(seq)
+	return &seqSource{next: next, stop: stop}
+}
+
+func (s *seqSource) GetToken() token.Token {
+	if s.next == nil {
+		return s.last
+	}
+
+	t, ok := s.next()
+	if !ok {
+		t = endOfFileAfter([]token.Token{s.last})
+	}
+
+	if t.Kind == token.TokEndOfFile {
+		s.stop()
+		s.next = nil
+	}
+
+	s.last = t
+	return t
+}
@@ -1,44 +1,64 @@
 package pipeline
 
 import (
+	"errors"
 	"fracta/internal/ast"
+	"fracta/internal/diag"
 	"fracta/internal/lexer"
 	"fracta/internal/parser"
 	"fracta/internal/sema"
 )
 
-// Does a single-source pass from file to AST
-func SingleFileReadingPipeline(pkgName, fname string) (ast.AST, error) {
+// Does a single-source pass from file to AST.
+// Diagnostics are returned separately, the AST is nil if any of them is an error.
+func SingleFileReadingPipeline(pkgName, fname string) (ast.AST, diag.ErrorList, error) {
 	lex, err := lexer.NewLexerFromFile(fname)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	defer lex.Close()
 
-	toks, err := lex.GetAllTokens()
-
-	if err != nil {
-		return nil, err
-	}
-
-	parser := parser.NewParser(toks, fname)
+	// Tokens are streamed into the parser, which still runs after lexing errors
+	// to report as many problems as possible
+	parser := parser.NewParserFromSource(lex, fname)
 	fsn, err := parser.Parse()
+	lexErr := lex.Errors()
 
-	if err != nil {
-		return nil, err
+	if lexErr != nil || err != nil {
+		diags := make(diag.ErrorList, 0)
+		for _, e := range []error{lexErr, err} {
+			if e == nil {
+				continue
+			}
+			_, list, other := diagnosticsOrError(e)
+			if other != nil {
+				return nil, nil, other
+			}
+			diags = append(diags, list...)
+		}
+		return nil, diags, nil
 	}
 
 	sm, err := sema.NewAnalyzer(pkgName, fsn)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	pfsn, err := sm.Analyze()
 
 	if err != nil {
-		return nil, err
+		return diagnosticsOrError(err)
 	}
 
-	return pfsn, nil
+	return pfsn, sm.Diagnostics(), nil
+}
+
+func diagnosticsOrError(err error) (ast.AST, diag.ErrorList, error) {
+	var list diag.ErrorList
+	if errors.As(err, &list) {
+		return nil, list, nil
+	}
+	return nil, nil, err
 }
@@ -0,0 +1,303 @@
+package sema
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"fracta/internal/types"
+	"go/constant"
+	"math"
+)
+
+func isArray(t types.Type) bool {
+	_, ok := types.Underlying(t).(*types.Array)
+	return ok
+}
+
+// Returns the element type of an array or slice, and the length of arrays or -1 for slices
+func elements(t types.Type) (types.Type, int64, bool) {
+	switch t := types.Underlying(t).(type) {
+	case *types.Array:
+		return t.Elem(), t.Len(), true
+	case *types.Slice:
+		return t.Elem(), -1, true
+	default:
+		return nil, 0, false
+	}
+}
+
+// Evaluates the length of an array type, which must be a non-negative integer constant
+func (a *SemanticAnalyzer) arrayLength(expr ast.Expression) (int64, bool) {
+	a.analyzeExpression(expr)
+
+	e := expr.ExprNode()
+	if !isResolved(e.Type) {
+		return 0, false
+	}
+
+	if e.Const != nil {
+		if v, exact := constant.Int64Val(constant.ToInt(e.Const)); exact && v >= 0 {
+			return v, true
+		}
+		a.addErrorExpr(diag.ErrInvalidArrayLength, e, "invalid array length %s", e.Const.String()).
+			WithNote("the length of an array is a non-negative integer")
+		return 0, false
+	}
+
+	a.addErrorExpr(diag.ErrInvalidArrayLength, e, "array length must be a constant").
+		WithHelp("use a slice for a length only known at run time")
+	return 0, false
+}
+
+// Checks an array literal. The first typed element decides the element type, and the others
+// must match it. A literal made of untyped constants only is itself untyped, until its context
+// gives it an element type.
+func (a *SemanticAnalyzer) analyzeArrayLiteral(e *ast.ArrayLiteral) {
+	if len(e.Elems) == 0 {
+		a.addErrorExpr(diag.ErrCannotInferType, &e.ExprBase, "cannot infer the type of an empty array literal").
+			WithHelp("declare the variable with its type and no initializer, arrays start as zeros")
+		return
+	}
+
+	resolved := true
+	for _, el := range e.Elems {
+		a.analyzeExpression(el)
+		resolved = resolved && isResolved(el.ExprNode().Type)
+	}
+	if !resolved {
+		return
+	}
+
+	n := int64(len(e.Elems))
+
+	var first ast.Expression
+	for _, el := range e.Elems {
+		if t := el.ExprNode().Type; !types.IsUntyped(t) && !isUntypedArray(t) {
+			first = el
+			break
+		}
+	}
+
+	if first == nil {
+		// Null is untyped too, but has no common type with anything
+		elem := e.Elems[0].ExprNode().Type
+		for _, el := range e.Elems {
+			if elem = unifyUntyped(elem, el.ExprNode().Type); elem == nil {
+				break
+			}
+		}
+
+		if elem == nil {
+			a.addErrorExpr(diag.ErrCannotInferType, &e.ExprBase, "cannot infer the element type of this array literal").
+				WithHelp("cast one of the elements to the element type, as in 'null as *i32'")
+			return
+		}
+		e.Type = types.NewArray(elem, n)
+		return
+	}
+
+	elem := first.ExprNode().Type
+	if elem == types.Void {
+		a.addErrorExpr(diag.ErrCannotInferType, first.ExprNode(), "array elements must produce a value")
+		return
+	}
+
+	for _, el := range e.Elems {
+		a.convertUntyped(el, elem)
+
+		et := el.ExprNode().Type
+		if !types.AssignableTo(et, elem) {
+			a.addErrorExpr(diag.ErrTypeMismatch, el.ExprNode(), "array element of type %q does not match the element type %q", et.String(), elem.String()).
+				WithLabel(first.ExprNode().Span, "the element type is decided by this element")
+		}
+	}
+
+	e.Type = types.NewArray(elem, n)
+}
+
+// Gives the elements of an untyped array literal the element type of the array it is used as
+func (a *SemanticAnalyzer) convertArrayLiteral(lit *ast.ArrayLiteral, target types.Type) {
+	if !isUntypedArray(lit.Type) || !isResolved(target) {
+		return
+	}
+
+	at := lit.Type.(*types.Array)
+	tt, ok := types.Underlying(target).(*types.Array)
+	if !ok || tt.Len() != at.Len() {
+		return
+	}
+
+	for _, el := range lit.Elems {
+		a.convertUntyped(el, tt.Elem())
+		if el.ExprNode().Type != tt.Elem() {
+			// Left untyped, the caller reports the mismatch
+			return
+		}
+	}
+
+	lit.Type = types.NewArray(tt.Elem(), at.Len())
+}
+
+func (a *SemanticAnalyzer) analyzeIndexedExpr(e *ast.Indexed) {
+	a.analyzeExpression(e.Indexee)
+	a.defaultUntyped(e.Indexee)
+	for _, idx := range e.Indices {
+		a.analyzeExpression(idx)
+	}
+
+	it := e.Indexee.ExprNode().Type
+	if !isResolved(it) {
+		return
+	}
+
+	elem, length, ok := elements(it)
+	if !ok {
+		a.addErrorExpr(diag.ErrNotIndexable, &e.ExprBase, "cannot index a value of type %q", it.String()).
+			WithLabel(e.Indexee.ExprNode().Span, "this is of type %q", it.String())
+		return
+	}
+
+	switch {
+	case len(e.Indices) == 0:
+		a.addErrorExpr(diag.ErrIndexCount, &e.ExprBase, "missing index")
+		return
+	case len(e.Indices) > 1:
+		err := a.addErrorExpr(diag.ErrIndexCount, &e.ExprBase, "expected 1 index, found %d", len(e.Indices)).
+			WithLabel(e.Indices[1].ExprNode().Span.To(e.Indices[len(e.Indices)-1].ExprNode().Span), "extra indices")
+		if _, _, ok := elements(elem); ok {
+			err.WithHelp("index one dimension at a time, as in 'a[i][j]'")
+		}
+		return
+	}
+
+	// The index can be at most length - 1, a slice has no known length
+	limit := int64(math.MaxInt64)
+	if length >= 0 {
+		limit = length - 1
+	}
+	a.checkIndex(e.Indices[0], limit, "index")
+	e.Type = elem
+}
+
+// Checks 'value[low:high]'. Slicing an array takes its address, slicing a slice reuses its elements.
+func (a *SemanticAnalyzer) analyzeSlicedExpr(e *ast.Sliced) {
+	a.analyzeExpression(e.Value)
+	a.defaultUntyped(e.Value)
+	for _, bound := range []ast.Expression{e.Low, e.High} {
+		if bound != nil {
+			a.analyzeExpression(bound)
+		}
+	}
+
+	vt := e.Value.ExprNode().Type
+	if !isResolved(vt) {
+		return
+	}
+
+	elem, length, ok := elements(vt)
+	if !ok {
+		a.addErrorExpr(diag.ErrNotIndexable, &e.ExprBase, "cannot slice a value of type %q", vt.String()).
+			WithLabel(e.Value.ExprNode().Span, "this is of type %q", vt.String())
+		return
+	}
+
+	// The slice has its type even with invalid bounds, avoiding cascading errors
+	e.Type = types.NewSlice(elem)
+
+	if length >= 0 && !a.checkAddressable(e.Value, &e.ExprBase, "slice") {
+		return
+	}
+
+	limit := length
+	if length < 0 {
+		limit = math.MaxInt64
+	}
+
+	low, lowConst := int64(0), true
+	if e.Low != nil {
+		low, lowConst = a.checkIndex(e.Low, limit, "slice bound")
+	}
+
+	high, highConst := length, length >= 0
+	if e.High != nil {
+		high, highConst = a.checkIndex(e.High, limit, "slice bound")
+	}
+
+	if lowConst && highConst && low > high {
+		a.addErrorExpr(diag.ErrIndexOutOfBounds, &e.ExprBase, "invalid slice bounds %d:%d, the low bound is above the high one", low, high)
+	}
+}
+
+// Checks an index or slice bound, which must be an integer. A constant must also range
+// from 0 to limit, which is math.MaxInt64 for slices. Returns the value of a constant.
+func (a *SemanticAnalyzer) checkIndex(idx ast.Expression, limit int64, what string) (int64, bool) {
+	a.convertUntyped(idx, types.I64)
+
+	e := idx.ExprNode()
+	if !isResolved(e.Type) {
+		return 0, false
+	}
+
+	if !types.IsInteger(e.Type) {
+		a.addErrorExpr(diag.ErrInvalidOperandType, e, "%s must be an integer, found %q", what, e.Type.String())
+		return 0, false
+	}
+
+	// Constants that do not fit in an i64 were reported and cleared by the conversion
+	c := indexConstant(idx)
+	if c == nil || c.Kind() != constant.Int {
+		return 0, false
+	}
+
+	v, exact := constant.Int64Val(c)
+	if !exact {
+		v = math.MaxInt64
+	}
+
+	switch {
+	case v < 0:
+		a.addErrorExpr(diag.ErrIndexOutOfBounds, e, "%s %s is negative", what, c.String())
+		return 0, false
+	case v > limit:
+		err := a.addErrorExpr(diag.ErrIndexOutOfBounds, e, "%s %s is out of bounds", what, c.String())
+		if limit < 0 {
+			err.WithNote("the array is empty")
+		} else {
+			err.WithNote("the %s ranges from 0 to %d", what, limit)
+		}
+		return 0, false
+	}
+	return v, true
+}
+
+// Returns the value of a constant index: an untyped constant, or a literal with an integer suffix
+func indexConstant(idx ast.Expression) constant.Value {
+	if c := idx.ExprNode().Const; c != nil {
+		return constant.ToInt(c)
+	}
+
+	lit, ok := idx.(*ast.Literal)
+	if !ok {
+		return nil
+	}
+
+	switch v := lit.Value.Value.(type) {
+	case int8:
+		return constant.MakeInt64(int64(v))
+	case int16:
+		return constant.MakeInt64(int64(v))
+	case int32:
+		return constant.MakeInt64(int64(v))
+	case int64:
+		return constant.MakeInt64(v)
+	case uint8:
+		return constant.MakeUint64(uint64(v))
+	case uint16:
+		return constant.MakeUint64(uint64(v))
+	case uint32:
+		return constant.MakeUint64(uint64(v))
+	case uint64:
+		return constant.MakeUint64(v)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,108 @@
+package sema
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"fracta/internal/token"
+	"fracta/internal/types"
+	"go/constant"
+	"math"
+)
+
+// Names of the builtin functions
+const builtinLen = "len"
+
+// Special float values, with the suffixes of float literals. go/constant has no infinities nor
+// NaN, so they are not folded, and are lowered as the literals declaring them.
+var floatConstants = []struct {
+	name  string
+	kind  token.TokenType
+	value any
+}{
+	{"inf", token.TokF64, math.Inf(1)},
+	{"infd", token.TokF64, math.Inf(1)},
+	{"inff", token.TokF32, float32(math.Inf(1))},
+	{"nan", token.TokF64, math.NaN()},
+	{"nand", token.TokF64, math.NaN()},
+	{"nanf", token.TokF32, float32(math.NaN())},
+}
+
+// Returns the scope holding the builtin functions and constants, the parent of every package
+// scope. Declarations can reuse the names of builtins, hiding them in their scope.
+func universeScope() *scope {
+	s := newScope(nil)
+	s.universe = true
+	_ = s.addSymbol(builtinLen, &builtinSymbol{name: builtinLen})
+
+	for _, c := range floatConstants {
+		lit := &ast.Literal{Value: token.Token{Kind: c.kind, Lexeme: c.name, Value: c.value}}
+		lit.Type = literalTypes[c.kind]
+		_ = s.addSymbol(c.name, &constantSymbol{symbolBase: symbolBase{decl: lit}, cType: lit.Type})
+	}
+	return s
+}
+
+func (a *SemanticAnalyzer) analyzeBuiltinCall(e *ast.Call, b *builtinSymbol) {
+	e.Builtin = b.name
+
+	for _, arg := range e.Args {
+		a.analyzeExpression(arg)
+		a.defaultUntyped(arg)
+	}
+
+	switch b.name {
+	case builtinLen:
+		a.analyzeLenCall(e)
+	}
+}
+
+// len(a) gives the number of elements of an array or slice as an i64. The length of an array is
+// a constant, folded when evaluating the argument has no side effects.
+func (a *SemanticAnalyzer) analyzeLenCall(e *ast.Call) {
+	e.Type = types.I64
+
+	if len(e.Args) != 1 {
+		a.addErrorExpr(diag.ErrArgumentCount, &e.ExprBase, "expected 1 argument, found %d", len(e.Args)).
+			WithNote("len takes the array or slice to measure")
+		return
+	}
+
+	at := e.Args[0].ExprNode().Type
+	if !isResolved(at) {
+		return
+	}
+
+	switch t := types.Underlying(at).(type) {
+	case *types.Array:
+		if isPure(e.Args[0]) {
+			e.Const = constant.MakeInt64(t.Len())
+		}
+	case *types.Slice:
+	default:
+		a.addErrorExpr(diag.ErrInvalidOperandType, e.Args[0].ExprNode(), "len needs an array or slice, found %q", at.String())
+	}
+}
+
+// Reports whether evaluating an expression only reads places: a variable, or the dereference or
+// indexing of one with constant or variable indices
+func isPure(expr ast.Expression) bool {
+	if expr.ExprNode().Const != nil {
+		return true
+	}
+
+	switch e := expr.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.Unary:
+		return e.Op.Kind == token.TokOpStar && isPure(e.SubExpr)
+	case *ast.Indexed:
+		for _, idx := range e.Indices {
+			if !isPure(idx) {
+				return false
+			}
+		}
+		return isPure(e.Indexee)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,244 @@
+package sema
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"fracta/internal/token"
+	"fracta/internal/types"
+	"go/constant"
+	gotoken "go/token"
+	"math"
+)
+
+// Shifting a constant further than this is reported instead of building a huge number
+const maxConstantShift = 1024
+
+// Bounds of every integer type, for range checks of constants
+var integerRanges = map[string][2]constant.Value{
+	"i8":  {constant.MakeInt64(math.MinInt8), constant.MakeInt64(math.MaxInt8)},
+	"i16": {constant.MakeInt64(math.MinInt16), constant.MakeInt64(math.MaxInt16)},
+	"i32": {constant.MakeInt64(math.MinInt32), constant.MakeInt64(math.MaxInt32)},
+	"i64": {constant.MakeInt64(math.MinInt64), constant.MakeInt64(math.MaxInt64)},
+	"u8":  {constant.MakeInt64(0), constant.MakeUint64(math.MaxUint8)},
+	"u16": {constant.MakeInt64(0), constant.MakeUint64(math.MaxUint16)},
+	"u32": {constant.MakeInt64(0), constant.MakeUint64(math.MaxUint32)},
+	"u64": {constant.MakeInt64(0), constant.MakeUint64(math.MaxUint64)},
+}
+
+// Gives an untyped constant expression the type its context expects, reporting values that do not fit.
+// Typed expressions and targets that cannot hold a number are left alone, the caller reports the mismatch.
+// Null takes the pointer type it is used as.
+func (a *SemanticAnalyzer) convertUntyped(expr ast.Expression, target types.Type) {
+	if lit, ok := expr.(*ast.ArrayLiteral); ok {
+		a.convertArrayLiteral(lit, target)
+		return
+	}
+
+	e := expr.ExprNode()
+	if e.Type == types.UntypedNull && types.IsPointer(target) {
+		e.Type = target
+		return
+	}
+
+	if !isUntypedNumber(e.Type) || !isResolved(target) || !types.IsNumeric(target) {
+		return
+	}
+
+	a.checkRepresentable(e, target)
+	e.Type = target
+}
+
+// Gives an untyped constant expression its default type, when nothing asks for another one
+func (a *SemanticAnalyzer) defaultUntyped(expr ast.Expression) {
+	if t := expr.ExprNode().Type; isUntypedNumber(t) || isUntypedArray(t) {
+		a.convertUntyped(expr, defaultType(t))
+	}
+}
+
+// Converts the constant of e to the target type. A constant that does not fit is reported and
+// cleared, so later checks do not report it again.
+func (a *SemanticAnalyzer) checkRepresentable(e *ast.ExprBase, target types.Type) {
+	if v, ok := representable(e.Const, target); ok {
+		e.Const = v
+		return
+	}
+
+	name := target.String()
+
+	switch {
+	case types.IsInteger(target) && constant.ToInt(e.Const).Kind() != constant.Int:
+		a.addErrorExpr(diag.ErrConstantNotRepresentable, e, "constant %s is not an integer and cannot be used as %s", e.Const.String(), name)
+	case types.IsInteger(target):
+		r := integerRanges[types.Underlying(target).String()]
+		a.addErrorExpr(diag.ErrConstantNotRepresentable, e, "constant %s overflows %s", e.Const.String(), name).
+			WithNote("the range of %s is %s to %s", name, r[0].String(), r[1].String())
+	default:
+		a.addErrorExpr(diag.ErrConstantNotRepresentable, e, "constant %s overflows %s", e.Const.String(), name)
+	}
+	e.Const = nil
+}
+
+// Returns the constant as a value of the numeric type, and whether it fits
+func representable(c constant.Value, t types.Type) (constant.Value, bool) {
+	if types.IsInteger(t) {
+		v := constant.ToInt(c)
+		if v.Kind() != constant.Int {
+			return nil, false
+		}
+
+		r := integerRanges[types.Underlying(t).String()]
+		if constant.Compare(v, gotoken.LSS, r[0]) || constant.Compare(v, gotoken.GTR, r[1]) {
+			return nil, false
+		}
+		return v, true
+	}
+
+	// Floats round to the nearest value, only going past the largest one is an error
+	if types.Underlying(t) == types.F32 {
+		f, _ := constant.Float32Val(c)
+		return c, !math.IsInf(float64(f), 0)
+	}
+	f, _ := constant.Float64Val(c)
+	return c, !math.IsInf(f, 0)
+}
+
+// Folds a unary operation on an untyped constant, reporting whether it could
+func (a *SemanticAnalyzer) foldUnary(e *ast.Unary) bool {
+	sub := e.SubExpr.ExprNode()
+
+	switch {
+	case e.Op.Kind == token.TokOpPlus:
+		e.Const = sub.Const
+	case e.Op.Kind == token.TokOpMinus:
+		e.Const = constant.UnaryOp(gotoken.SUB, sub.Const, 0)
+	case e.Op.Kind == token.TokOpTilde && sub.Type == types.UntypedInt:
+		e.Const = constant.UnaryOp(gotoken.XOR, sub.Const, 0)
+	default:
+		return false
+	}
+
+	e.Type = sub.Type
+	return true
+}
+
+var foldedOperators = map[token.TokenType]gotoken.Token{
+	token.TokOpPlus:       gotoken.ADD,
+	token.TokOpMinus:      gotoken.SUB,
+	token.TokOpStar:       gotoken.MUL,
+	token.TokOpSlash:      gotoken.QUO,
+	token.TokOpMod:        gotoken.REM,
+	token.TokOpAmpersand:  gotoken.AND,
+	token.TokOpPipe:       gotoken.OR,
+	token.TokOpCaret:      gotoken.XOR,
+	token.TokOpShiftLeft:  gotoken.SHL,
+	token.TokOpShiftRight: gotoken.SHR,
+}
+
+// Folds a binary operation on two untyped constants, reporting whether it could.
+// Comparisons, logical operators and invalid operands are left to the regular checks.
+func (a *SemanticAnalyzer) foldBinary(e *ast.Binary) bool {
+	op, ok := foldedOperators[e.Op.Kind]
+	if !ok {
+		return false
+	}
+
+	l, r := e.Left.ExprNode(), e.Right.ExprNode()
+	float := l.Type == types.UntypedFloat || r.Type == types.UntypedFloat
+
+	var v constant.Value
+
+	switch e.Op.Kind {
+	case token.TokOpSlash, token.TokOpMod:
+		if constant.Sign(r.Const) == 0 {
+			a.addErrorExpr(diag.ErrDivisionByZero, &e.ExprBase, "division by zero in a constant expression").
+				WithLabel(r.Span, "this is zero")
+			e.Type = untypedNumber(float)
+			e.Const = l.Const
+			return true
+		}
+
+		switch {
+		case !float && op == gotoken.QUO:
+			v = constant.BinaryOp(l.Const, gotoken.QUO_ASSIGN, r.Const) // Truncating integer division
+		case float && op == gotoken.REM:
+			x, _ := constant.Float64Val(l.Const)
+			y, _ := constant.Float64Val(r.Const)
+			v = constant.MakeFloat64(math.Mod(x, y))
+		default:
+			v = constant.BinaryOp(l.Const, op, r.Const)
+		}
+
+	case token.TokOpAmpersand, token.TokOpPipe, token.TokOpCaret:
+		if float {
+			return false
+		}
+		v = constant.BinaryOp(l.Const, op, r.Const)
+
+	case token.TokOpShiftLeft, token.TokOpShiftRight:
+		if float {
+			return false
+		}
+
+		count, exact := constant.Uint64Val(r.Const)
+		if !exact || count > maxConstantShift {
+			a.addErrorExpr(diag.ErrInvalidOperandType, r, "invalid shift count %s for a constant", r.Const.String()).
+				WithNote("constant shift counts range from 0 to %d", maxConstantShift)
+			e.Type = l.Type
+			e.Const = l.Const
+			return true
+		}
+		v = constant.Shift(l.Const, op, uint(count))
+		float = false
+
+	default:
+		v = constant.BinaryOp(l.Const, op, r.Const)
+	}
+
+	e.Type = untypedNumber(float)
+	e.Const = v
+	return true
+}
+
+// Reports whether the type is that of an untyped number constant, whose value is known exactly
+func isUntypedNumber(t types.Type) bool {
+	return t == types.UntypedInt || t == types.UntypedFloat
+}
+
+// Reports whether the type is that of an array literal made of untyped constants only,
+// whose element type is decided by the context like for a single constant
+func isUntypedArray(t types.Type) bool {
+	at, ok := t.(*types.Array)
+	return ok && (isUntypedNumber(at.Elem()) || isUntypedArray(at.Elem()))
+}
+
+// Returns the default type of an untyped constant or untyped array
+func defaultType(t types.Type) types.Type {
+	if at, ok := t.(*types.Array); ok {
+		return types.NewArray(defaultType(at.Elem()), at.Len())
+	}
+	return types.Default(t)
+}
+
+// Returns the untyped type holding values of both untyped types, nil if there is none
+func unifyUntyped(x, y types.Type) types.Type {
+	if isUntypedNumber(x) && isUntypedNumber(y) {
+		return untypedNumber(x == types.UntypedFloat || y == types.UntypedFloat)
+	}
+
+	xa, xok := x.(*types.Array)
+	ya, yok := y.(*types.Array)
+	if !xok || !yok || xa.Len() != ya.Len() {
+		return nil
+	}
+	if elem := unifyUntyped(xa.Elem(), ya.Elem()); elem != nil {
+		return types.NewArray(elem, xa.Len())
+	}
+	return nil
+}
+
+func untypedNumber(float bool) types.Type {
+	if float {
+		return types.UntypedFloat
+	}
+	return types.UntypedInt
+}
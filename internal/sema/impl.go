@@ -5,18 +5,68 @@ import (
 	"fracta/internal/ast"
 	"fracta/internal/diag"
 	"fracta/internal/token"
+	"fracta/internal/types"
+	"go/constant"
+	"slices"
 )
 
-func (a *SemanticAnalyzer) addErrorStmt(stmt *ast.StmtBase, f string, v ...any) {
-	msg := fmt.Sprintf(f, v...)
-	o := diag.CreateError(msg, a.currentFile, stmt.Line)
-	a.errors = append(a.errors, o)
+// Reports whether the type of an expression is known, an error was already reported otherwise
+func isResolved(t types.Type) bool {
+	return t != nil && t != types.Invalid
 }
 
-func (a *SemanticAnalyzer) addErrorExpr(expr *ast.ExprBase, f string, v ...any) {
+// Resolves a type written in the source, reporting names that do not denote a type
+func (a *SemanticAnalyzer) resolveType(t ast.Type) types.Type {
+	switch t := t.(type) {
+	case *ast.TypeName:
+		if b, ok := types.Lookup(t.Name.Identifier); ok {
+			return b
+		}
+		a.addErrorSpan(diag.ErrUndefinedSymbol, t.Name.Span, "undefined type: %s", t.Name.Identifier)
+		return types.Invalid
+	case *ast.PointerType:
+		elem := a.resolveType(t.Elem)
+		if !isResolved(elem) {
+			return types.Invalid
+		}
+		return types.NewPointer(elem)
+	case *ast.ArrayType:
+		n, ok := a.arrayLength(t.Len)
+		elem := a.resolveType(t.Elem)
+		if !ok || !isResolved(elem) {
+			return types.Invalid
+		}
+		return types.NewArray(elem, n)
+	case *ast.SliceType:
+		elem := a.resolveType(t.Elem)
+		if !isResolved(elem) {
+			return types.Invalid
+		}
+		return types.NewSlice(elem)
+	default:
+		panic(fmt.Sprintf("unexpected type node %T", t))
+	}
+}
+
+func (a *SemanticAnalyzer) addErrorSpan(code diag.Code, span token.Span, f string, v ...any) *diag.ErrorContainer {
 	msg := fmt.Sprintf(f, v...)
-	o := diag.CreateError(msg, a.currentFile, expr.Line)
+	o := diag.CreateError(code, msg, span)
 	a.errors = append(a.errors, o)
+	return o
+}
+
+func (a *SemanticAnalyzer) addErrorStmt(code diag.Code, stmt *ast.StmtBase, f string, v ...any) *diag.ErrorContainer {
+	return a.addErrorSpan(code, stmt.Span, f, v...)
+}
+
+func (a *SemanticAnalyzer) addErrorExpr(code diag.Code, expr *ast.ExprBase, f string, v ...any) *diag.ErrorContainer {
+	return a.addErrorSpan(code, expr.Span, f, v...)
+}
+
+// Reports a declaration reusing the name of a symbol in scope
+func (a *SemanticAnalyzer) addRedefinition(span token.Span, name string, prev symbol) {
+	a.addErrorSpan(diag.ErrRedefinition, span, "symbol redefinition: %s", name).
+		WithLabel(prev.getSymbolBase().span, "previous definition of %s here", name)
 }
 
 func (a *SemanticAnalyzer) createScope() {
@@ -33,38 +83,64 @@ func (a *SemanticAnalyzer) Analyze() ([]*ast.FileSourceNode, error) {
 		a.populatePackageSymbolTable(fileAst)
 	}
 
-	if len(a.errors) == 0 {
+	if !a.Diagnostics().HasErrors() {
 		for _, fn := range a.packageAsts {
 			a.currentFile = fn.Filename
 			a.analyzeFileNode(fn)
 		}
 	}
 
-	if len(a.errors) > 0 {
-		return nil, diag.ErrorList(a.errors)
+	if a.Diagnostics().HasErrors() {
+		return nil, a.Diagnostics()
 	}
 
 	return a.packageAsts, nil
 }
 
+// Returns every diagnostic reported so far, warnings included
+func (a *SemanticAnalyzer) Diagnostics() diag.ErrorList {
+	return diag.ErrorList(a.errors)
+}
+
 func (a *SemanticAnalyzer) populatePackageSymbolTable(fileTree *ast.FileSourceNode) {
 	for _, stmt := range fileTree.Statements {
 		switch s := stmt.(type) {
 		case *ast.FunctionDeclaration:
 			a.populateFunctionDecl(s)
+		case *ast.VariableDeclaration:
+			a.addErrorStmt(diag.ErrUnsupported, &s.StmtBase, "global variables are not supported yet")
 		default:
-			a.addErrorStmt(stmt.StmtNode(), "invalid statement, only declarations are allowed in top-level scope")
+			a.addErrorStmt(diag.ErrInvalidTopLevel, stmt.StmtNode(), "invalid statement, only declarations are allowed in top-level scope")
 		}
 	}
 }
 
 func (a *SemanticAnalyzer) populateFunctionDecl(fd *ast.FunctionDeclaration) {
-	err := a.pkgScope.addSymbol(fd.Name.Identifier, &functionSymbol{
-		symbolBase: symbolBase{pkg: a.packageName},
-		fType:      ast.FuncDeclToFuncType(fd),
+	name := fd.Name.Identifier
+
+	if prev, ok := a.pkgScope.getDeclared(name); ok {
+		a.addRedefinition(fd.Name.Span, name, prev)
+		return
+	}
+
+	params := make([]types.Type, len(fd.Args))
+	for i, arg := range fd.Args {
+		params[i] = a.resolveType(arg.Type)
+	}
+
+	result := types.Type(types.Void)
+	if fd.ReturnType != nil {
+		result = a.resolveType(fd.ReturnType)
+	}
+
+	fd.Signature = types.NewFunction(params, result)
+
+	err := a.pkgScope.addSymbol(name, &functionSymbol{
+		symbolBase: symbolBase{pkg: a.packageName, span: fd.Name.Span, decl: fd},
+		fType:      fd.Signature,
 	})
 	if err != nil {
-		a.addErrorStmt(&fd.StmtBase, "symbol redefinition: %s", fd.Name.Identifier)
+		a.addErrorSpan(diag.ErrRedefinition, fd.Name.Span, "symbol redefinition: %s", name)
 	}
 }
 
@@ -79,21 +155,62 @@ func (a *SemanticAnalyzer) analyzeTopLevelStatement(st ast.Statement) {
 	case *ast.FunctionDeclaration:
 		a.analyzeFunctionDecl(s)
 	default:
-		a.addErrorStmt(st.StmtNode(), "invalid top level statement")
+		a.addErrorStmt(diag.ErrInvalidTopLevel, st.StmtNode(), "invalid top level statement")
 	}
 }
 
 func (a *SemanticAnalyzer) analyzeFunctionDecl(fd *ast.FunctionDeclaration) {
 	a.currentFunction = fd
+	a.loops = nil
 	defer func() { a.currentFunction = nil }()
 
 	if fd.Body != nil {
 		body, ok := fd.Body.(*ast.BlockStatement)
 		if !ok {
-			a.addErrorStmt(&fd.StmtBase, "only block statements are allowed in a function body")
+			a.addErrorStmt(diag.ErrInvalidFunctionBody, &fd.StmtBase, "only block statements are allowed in a function body")
 			return
 		}
+
+		// Parameters live in a scope of their own, around the body
+		a.createScope()
+		defer a.dropScope()
+
+		a.declareParameters(fd)
 		a.analyzeBlockStatement(body)
+
+		if result := fd.Signature.Result(); result != types.Void && !alwaysExits(body) {
+			a.addErrorSpan(diag.ErrMissingReturn, closingBrace(body), "missing return at the end of a function returning %q", result.String()).
+				WithLabel(fd.Name.Span, "%s is declared here", fd.Name.Identifier).
+				WithHelp("end every path through the body with a return statement")
+		}
+	}
+}
+
+// Returns the span of the '}' a block ends with
+func closingBrace(bl *ast.BlockStatement) token.Span {
+	brace := bl.Span
+	brace.Start = brace.End
+	brace.Start.Offset -= len("}")
+	brace.Start.Column -= len("}")
+	return brace
+}
+
+// Adds the parameters of a function to the current scope, they behave like let bindings
+func (a *SemanticAnalyzer) declareParameters(fd *ast.FunctionDeclaration) {
+	for i := range fd.Args {
+		arg := &fd.Args[i]
+		name := arg.Name.Identifier
+
+		if prev, ok := a.currentScope.getDeclared(name); ok {
+			a.addRedefinition(arg.Name.Span, name, prev)
+			continue
+		}
+
+		_ = a.currentScope.addSymbol(name, &variableSymbol{
+			symbolBase: symbolBase{pkg: a.packageName, span: arg.Name.Span, decl: arg},
+			vType:      fd.Signature.Params()[i],
+			mutable:    false,
+		})
 	}
 }
 
@@ -105,30 +222,57 @@ func (a *SemanticAnalyzer) analyzeStatement(st ast.Statement) {
 		a.analyzeBlockStatement(s)
 	case *ast.ExpressionStatement:
 		a.analyzeExpressionStatement(s)
+	case *ast.VariableDeclaration:
+		a.analyzeVariableDecl(s)
+	case *ast.IfStatement:
+		a.analyzeIfStatement(s)
+	case *ast.WhileStatement:
+		a.analyzeCondition(s.Condition)
+		a.analyzeLoopBody(s, s.Label, s.Body)
+	case *ast.ForStatement:
+		a.analyzeForStatement(s)
+	case *ast.LoopStatement:
+		a.analyzeLoopBody(s, s.Label, s.Body)
+	case *ast.BreakStatement:
+		s.Target = a.resolveJump(&s.StmtBase, "break", s.Label)
+	case *ast.ContinueStatement:
+		s.Target = a.resolveJump(&s.StmtBase, "continue", s.Label)
 	default:
-		a.addErrorStmt(st.StmtNode(), "invalid statement in this position")
+		a.addErrorStmt(diag.ErrUnsupported, st.StmtNode(), "invalid statement in this position")
 	}
 }
 
 func (a *SemanticAnalyzer) analyzeReturnStatement(ret *ast.ReturnStatement) {
-	if a.currentFunction.ReturnType == nil {
+	result := a.currentFunction.Signature.Result()
+
+	if result == types.Void {
 		if ret.Value != nil {
-			a.addErrorStmt(&ret.StmtBase, "return has value in a void function")
+			a.addErrorStmt(diag.ErrReturnValueInVoid, &ret.StmtBase, "return has value in a void function")
 		}
 		return
 	}
 
-	if ret.Value != nil {
-		a.analyzeExpression(ret.Value)
+	if ret.Value == nil {
+		a.addErrorStmt(diag.ErrMissingReturnValue, &ret.StmtBase, "missing return value in a function returning %q", result.String())
+		return
 	}
 
+	a.analyzeExpression(ret.Value)
+	a.convertUntyped(ret.Value, result)
+
 	retType := ret.Value.ExprNode().Type
+	if !isResolved(retType) {
+		return
+	}
 
-	if !ast.CompareTypes(retType, a.currentFunction.ReturnType) {
-		a.addErrorStmt(&ret.StmtBase, "return type mismatch, expression of type %q, expected %q", retType.String(), a.currentFunction.ReturnType.String())
+	if !isResolved(result) {
 		return
 	}
 
+	if !types.AssignableTo(retType, result) {
+		a.addErrorStmt(diag.ErrReturnTypeMismatch, &ret.StmtBase, "return type mismatch, expression of type %q, expected %q", retType.String(), result.String())
+		return
+	}
 }
 
 func (a *SemanticAnalyzer) analyzeBlockStatement(bl *ast.BlockStatement) {
@@ -140,8 +284,208 @@ func (a *SemanticAnalyzer) analyzeBlockStatement(bl *ast.BlockStatement) {
 	}
 }
 
+// Reports whether control never reaches the statement following this one
+func alwaysExits(st ast.Statement) bool {
+	switch s := st.(type) {
+	case *ast.ReturnStatement:
+		return true
+	case *ast.BlockStatement:
+		for _, inner := range s.Body {
+			if alwaysExits(inner) {
+				return true
+			}
+		}
+		return false
+	case *ast.IfStatement:
+		return s.Else != nil && alwaysExits(s.Then) && alwaysExits(s.Else)
+	case *ast.BreakStatement, *ast.ContinueStatement:
+		return true
+	case *ast.LoopStatement:
+		return !breaksOut(s.Body, s)
+	case *ast.ForStatement:
+		return s.Condition == nil && !breaksOut(s.Body, s)
+	default:
+		return false
+	}
+}
+
+// Reports whether a break targeting the loop appears anywhere within the statement
+func breaksOut(st ast.Statement, loop ast.Statement) bool {
+	switch s := st.(type) {
+	case *ast.BreakStatement:
+		return s.Target == loop
+	case *ast.BlockStatement:
+		return slices.ContainsFunc(s.Body, func(inner ast.Statement) bool { return breaksOut(inner, loop) })
+	case *ast.IfStatement:
+		return breaksOut(s.Then, loop) || (s.Else != nil && breaksOut(s.Else, loop))
+	case *ast.WhileStatement:
+		return breaksOut(s.Body, loop)
+	case *ast.ForStatement:
+		return breaksOut(s.Body, loop)
+	case *ast.LoopStatement:
+		return breaksOut(s.Body, loop)
+	default:
+		return false
+	}
+}
+
+func (a *SemanticAnalyzer) analyzeForStatement(st *ast.ForStatement) {
+	// Locals of the init statement live until the end of the loop
+	a.createScope()
+	defer a.dropScope()
+
+	if st.Init != nil {
+		a.analyzeStatement(st.Init)
+	}
+	if st.Condition != nil {
+		a.analyzeCondition(st.Condition)
+	}
+	if st.Post != nil {
+		a.analyzeExpression(st.Post)
+	}
+
+	a.analyzeLoopBody(st, st.Label, st.Body)
+}
+
+// Analyzes the body of a loop, which break and continue may target
+func (a *SemanticAnalyzer) analyzeLoopBody(loop ast.Statement, label *token.Token, body *ast.BlockStatement) {
+	if label != nil {
+		for _, l := range a.loops {
+			if l.label != nil && l.label.Identifier == label.Identifier {
+				a.addErrorSpan(diag.ErrRedefinition, label.Span, "label %s is already used by an enclosing loop", label.Identifier).
+					WithLabel(l.label.Span, "previous definition of %s here", label.Identifier)
+				break
+			}
+		}
+	}
+
+	a.loops = append(a.loops, loopContext{label: label, stmt: loop})
+	defer func() { a.loops = a.loops[:len(a.loops)-1] }()
+
+	a.analyzeBlockStatement(body)
+}
+
+// Returns the loop targeted by a break or continue, nil after reporting an error
+func (a *SemanticAnalyzer) resolveJump(st *ast.StmtBase, kw string, label *token.Token) ast.Statement {
+	if len(a.loops) == 0 {
+		a.addErrorStmt(diag.ErrJumpOutsideLoop, st, "%s outside of a loop", kw)
+		return nil
+	}
+
+	if label == nil {
+		return a.loops[len(a.loops)-1].stmt
+	}
+
+	for i := len(a.loops) - 1; i >= 0; i-- {
+		if l := a.loops[i].label; l != nil && l.Identifier == label.Identifier {
+			return a.loops[i].stmt
+		}
+	}
+
+	a.addErrorSpan(diag.ErrUndefinedLabel, label.Span, "undefined label %s", label.Identifier).
+		WithNote("a label must name one of the loops enclosing the %s", kw)
+	return nil
+}
+
+func (a *SemanticAnalyzer) analyzeIfStatement(st *ast.IfStatement) {
+	a.analyzeCondition(st.Condition)
+
+	// Each branch is a block with its own scope
+	a.analyzeBlockStatement(st.Then)
+	if st.Else != nil {
+		a.analyzeStatement(st.Else)
+	}
+}
+
+// Checks that a condition is a bool, integers and pointers are not implicitly tested against zero
+func (a *SemanticAnalyzer) analyzeCondition(cond ast.Expression) {
+	a.analyzeExpression(cond)
+
+	ct := cond.ExprNode().Type
+	if !isResolved(ct) || types.IsBool(ct) {
+		return
+	}
+
+	err := a.addErrorExpr(diag.ErrNonBoolCondition, cond.ExprNode(), "condition must be of type \"bool\", found %q", ct.String())
+	switch {
+	case types.IsNumeric(ct):
+		err.WithHelp("compare it against zero explicitly")
+	case types.IsPointer(ct):
+		err.WithHelp("compare it against null explicitly")
+	}
+}
+
+func (a *SemanticAnalyzer) analyzeVariableDecl(vd *ast.VariableDeclaration) {
+	name := vd.Name.Identifier
+
+	// The initializer is checked first, a variable is not in scope within its own initializer
+	if vd.Value != nil {
+		a.analyzeExpression(vd.Value)
+	}
+
+	vd.VarType = types.Invalid
+	if vd.Type != nil {
+		vd.VarType = a.resolveType(vd.Type)
+	}
+
+	switch {
+	case vd.Value == nil && !vd.Mutable:
+		a.addErrorStmt(diag.ErrMissingInitializer, &vd.StmtBase, "let binding %s needs an initializer", name).
+			WithHelp("use 'var' for a variable that is assigned later")
+
+	case vd.Value == nil && vd.Type == nil:
+		a.addErrorStmt(diag.ErrMissingInitializer, &vd.StmtBase, "variable %s needs a type or an initializer", name)
+
+	case vd.Value != nil:
+		vt := vd.Value.ExprNode().Type
+		if !isResolved(vt) {
+			break
+		}
+
+		if vd.Type == nil {
+			a.defaultUntyped(vd.Value)
+			vt = vd.Value.ExprNode().Type
+
+			switch vt {
+			case types.UntypedNull:
+				a.addErrorExpr(diag.ErrCannotInferType, vd.Value.ExprNode(), "cannot infer the type of %s from null", name).
+					WithHelp("write the type of the variable, as in 'var %s ptr = null;'", name)
+			case types.Void:
+				a.addErrorExpr(diag.ErrCannotInferType, vd.Value.ExprNode(), "cannot infer the type of %s, the initializer produces no value", name)
+			default:
+				vd.VarType = vt
+			}
+			break
+		}
+
+		if !isResolved(vd.VarType) {
+			break
+		}
+
+		a.convertUntyped(vd.Value, vd.VarType)
+		vt = vd.Value.ExprNode().Type
+
+		if !types.AssignableTo(vt, vd.VarType) {
+			a.addErrorExpr(diag.ErrTypeMismatch, vd.Value.ExprNode(), "cannot initialize %s of type %q with a value of type %q", name, vd.VarType.String(), vt.String()).
+				WithLabel(vd.Name.Span, "%s declared as %q here", name, vd.VarType.String())
+		}
+	}
+
+	if prev, ok := a.currentScope.getDeclared(name); ok {
+		a.addRedefinition(vd.Name.Span, name, prev)
+		return
+	}
+
+	_ = a.currentScope.addSymbol(name, &variableSymbol{
+		symbolBase: symbolBase{pkg: a.packageName, span: vd.Name.Span, decl: vd},
+		vType:      vd.VarType,
+		mutable:    vd.Mutable,
+	})
+}
+
 func (a *SemanticAnalyzer) analyzeExpressionStatement(est *ast.ExpressionStatement) {
 	a.analyzeExpression(est.Expression)
+	a.defaultUntyped(est.Expression)
 }
 
 func (a *SemanticAnalyzer) analyzeExpression(expr ast.Expression) {
@@ -154,45 +498,125 @@ func (a *SemanticAnalyzer) analyzeExpression(expr ast.Expression) {
 		a.analyzeUnaryExpr(e)
 	case *ast.Binary:
 		a.analyzeBinaryExpr(e)
+	case *ast.Assignment:
+		a.analyzeAssignmentExpr(e)
 	case *ast.Call:
 		a.analyzeCallExpr(e)
+	case *ast.Cast:
+		a.analyzeCastExpr(e)
 	case *ast.Indexed:
 		a.analyzeIndexedExpr(e)
+	case *ast.Sliced:
+		a.analyzeSlicedExpr(e)
+	case *ast.ArrayLiteral:
+		a.analyzeArrayLiteral(e)
 	default:
-		a.addErrorExpr(expr.ExprNode(), "unknown expression kind")
+		a.addErrorExpr(diag.ErrUnsupported, expr.ExprNode(), "unknown expression kind")
 	}
 }
 
+var literalTypes = map[token.TokenType]types.Type{
+	token.TokI8:  types.I8,
+	token.TokI16: types.I16,
+	token.TokI32: types.I32,
+	token.TokI64: types.I64,
+
+	token.TokU8:  types.U8,
+	token.TokU16: types.U16,
+	token.TokU32: types.U32,
+	token.TokU64: types.U64,
+
+	token.TokF32: types.F32,
+	token.TokF64: types.F64,
+
+	token.TokInt:   types.UntypedInt,
+	token.TokFloat: types.UntypedFloat,
+
+	token.TokChar:   types.Char,
+	token.TokString: types.Str,
+
+	token.TokKwTrue:  types.Bool,
+	token.TokKwFalse: types.Bool,
+	token.TokKwNull:  types.UntypedNull,
+}
+
 func (a *SemanticAnalyzer) analyzeLiteralExpr(e *ast.Literal) {
-	etype, ok := ast.TokenLiteralMap[e.Value.Kind]
+	etype, ok := literalTypes[e.Value.Kind]
 	if !ok {
-		a.addErrorExpr(&e.ExprBase, "literal not yet supported: %s", e.Value.String())
+		a.addErrorExpr(diag.ErrUnsupported, &e.ExprBase, "literal not yet supported: %s", e.Value.String())
 		return
 	}
 
 	e.Type = etype
+	if isUntypedNumber(etype) {
+		e.Const = e.Value.Value.(constant.Value)
+	}
 }
 
 func (a *SemanticAnalyzer) analyzeIdentifierExpr(e *ast.Identifier) {
 	sym, ok := a.currentScope.getSymbol(e.Ident.Identifier)
 	if !ok {
-		a.addErrorExpr(&e.ExprBase, "used but not defined: %s", e.Ident.Identifier)
+		a.addErrorExpr(diag.ErrUndefinedSymbol, &e.ExprBase, "used but not defined: %s", e.Ident.Identifier)
+		return
+	}
+	if sym.getSymbolKind() == symbolBuiltin {
+		a.addErrorExpr(diag.ErrBuiltinValue, &e.ExprBase, "builtin function %s must be called", e.Ident.Identifier)
 		return
 	}
 	e.Type = sym.getExprType()
+	e.Decl = sym.getSymbolBase().decl
 }
 
 func (a *SemanticAnalyzer) analyzeUnaryExpr(e *ast.Unary) {
 	a.analyzeExpression(e.SubExpr)
+	if !isResolved(e.SubExpr.ExprNode().Type) {
+		return
+	}
+
+	st := e.SubExpr.ExprNode().Type
+	if isUntypedNumber(st) && a.foldUnary(e) {
+		return
+	}
 
 	switch e.Op.Kind {
 	case token.TokOpPlus, token.TokOpMinus:
-		if !ast.IsNumeric(e.Type) {
-			a.addErrorExpr(&e.ExprBase, "non-numeric expression type for unary expression")
+		if !types.IsNumeric(st) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "non-numeric expression type for unary expression")
 			return
 		}
+		e.Type = st
+	case token.TokOpBang:
+		if !types.IsBool(st) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "operator %s needs a bool operand, found %q", e.Op.Kind.Symbol(), st.String())
+			return
+		}
+		e.Type = st
+	case token.TokOpTilde:
+		if !types.IsInteger(st) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "operator %s needs an integer operand, found %q", e.Op.Kind.Symbol(), st.String())
+			return
+		}
+		e.Type = st
+	case token.TokOpAmpersand:
+		if !a.checkAddressable(e.SubExpr, &e.ExprBase, "take the address of") {
+			return
+		}
+		e.Type = types.NewPointer(st)
+	case token.TokOpStar:
+		pt, ok := types.Underlying(st).(*types.Pointer)
+		if !ok {
+			err := a.addErrorExpr(diag.ErrInvalidDereference, &e.ExprBase, "cannot dereference a value of type %q", st.String())
+			switch {
+			case st == types.UntypedNull:
+				err.WithNote("null does not point to any value")
+			case types.IsPointer(st):
+				err.WithHelp("cast it to a typed pointer first, as in '*(p as *i32)'")
+			}
+			return
+		}
+		e.Type = pt.Elem()
 	default:
-		a.addErrorExpr(&e.ExprBase, "invalid operator for unary expression")
+		a.addErrorExpr(diag.ErrInvalidOperator, &e.ExprBase, "invalid operator for unary expression")
 		return
 	}
 }
@@ -200,17 +624,365 @@ func (a *SemanticAnalyzer) analyzeUnaryExpr(e *ast.Unary) {
 func (a *SemanticAnalyzer) analyzeBinaryExpr(e *ast.Binary) {
 	a.analyzeExpression(e.Left)
 	a.analyzeExpression(e.Right)
+	if !isResolved(e.Left.ExprNode().Type) || !isResolved(e.Right.ExprNode().Type) {
+		return
+	}
+
+	if isUntypedNumber(e.Left.ExprNode().Type) && isUntypedNumber(e.Right.ExprNode().Type) && a.foldBinary(e) {
+		return
+	}
 
-	if !ast.CompareTypes(e.Left.ExprNode().Type, e.Right.ExprNode().Type) {
-		a.addErrorExpr(&e.ExprBase, "mismatched types for expression")
+	a.unifyOperands(e, e.Op.Kind)
+	a.checkBinaryOperands(e, e.Op.Kind)
+}
+
+// Gives the untyped operands of a binary operation the type of the other operand, null included.
+// When both are untyped they take a common default type, a float one if either is a float.
+func (a *SemanticAnalyzer) unifyOperands(e *ast.Binary, op token.TokenType) {
+	lt, rt := e.Left.ExprNode().Type, e.Right.ExprNode().Type
+	lok, rok := types.IsUntyped(lt), types.IsUntyped(rt)
+
+	if op == token.TokOpShiftLeft || op == token.TokOpShiftRight {
+		// The count is unsigned whatever the type of the shifted value
+		a.convertUntyped(e.Right, types.U64)
+		a.defaultUntyped(e.Left)
 		return
 	}
+
+	switch {
+	case lok && rok:
+		common := types.Default(untypedNumber(lt == types.UntypedFloat || rt == types.UntypedFloat))
+		a.convertUntyped(e.Left, common)
+		a.convertUntyped(e.Right, common)
+	case lok:
+		a.convertUntyped(e.Left, rt)
+	case rok:
+		a.convertUntyped(e.Right, lt)
+	}
+}
+
+// Checks the operand types of a binary operation, whose operator is given separately
+// so compound assignments can share the rules. Sets the result type if they are valid.
+func (a *SemanticAnalyzer) checkBinaryOperands(e *ast.Binary, op token.TokenType) {
+	lt, rt := e.Left.ExprNode().Type, e.Right.ExprNode().Type
+
+	switch op {
+	case token.TokOpEq, token.TokOpNotEq:
+		if !types.AssignableTo(rt, lt) && !types.AssignableTo(lt, rt) {
+			a.addMismatchedOperands(e)
+			return
+		}
+		if !types.IsComparable(lt) || !types.IsComparable(rt) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "values of type %q cannot be compared with %s", lt.String(), e.Op.Kind.Symbol())
+			return
+		}
+		e.Type = types.Bool
+
+	case token.TokOpLessThan, token.TokOpGreaterThan, token.TokOpLessEqual, token.TokOpGreaterEqual:
+		if !types.Identical(lt, rt) {
+			a.addMismatchedOperands(e)
+			return
+		}
+		if !types.IsNumeric(lt) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "values of type %q cannot be ordered, %s needs numeric operands", lt.String(), e.Op.Kind.Symbol())
+			return
+		}
+		e.Type = types.Bool
+
+	case token.TokOpLogicalAnd, token.TokOpLogicalOr:
+		if !types.IsBool(lt) || !types.IsBool(rt) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "operator %s needs bool operands, found %q and %q", e.Op.Kind.Symbol(), lt.String(), rt.String())
+			return
+		}
+		e.Type = lt
+
+	case token.TokOpAmpersand, token.TokOpPipe, token.TokOpCaret:
+		if !types.Identical(lt, rt) {
+			a.addMismatchedOperands(e)
+			return
+		}
+		if !types.IsInteger(lt) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "operator %s needs integer operands, found %q", e.Op.Kind.Symbol(), lt.String())
+			return
+		}
+		e.Type = lt
+
+	case token.TokOpShiftLeft, token.TokOpShiftRight:
+		if !types.IsInteger(lt) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "operator %s needs an integer to shift, found %q", e.Op.Kind.Symbol(), lt.String()).
+				WithLabel(e.Left.ExprNode().Span, "this is of type %q", lt.String())
+			return
+		}
+		if !types.IsUnsigned(rt) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "shift count must be an unsigned integer, found %q", rt.String()).
+				WithLabel(e.Right.ExprNode().Span, "this is of type %q", rt.String())
+			return
+		}
+		e.Type = lt
+
+	default:
+		if !types.Identical(lt, rt) {
+			a.addMismatchedOperands(e)
+			return
+		}
+		if !types.IsNumeric(lt) {
+			a.addErrorExpr(diag.ErrInvalidOperandType, &e.ExprBase, "non-numeric expression type for binary expression")
+			return
+		}
+		e.Type = lt
+	}
+}
+
+func (a *SemanticAnalyzer) addMismatchedOperands(e *ast.Binary) {
+	lt, rt := e.Left.ExprNode().Type, e.Right.ExprNode().Type
+
+	a.addErrorExpr(diag.ErrMismatchedOperands, &e.ExprBase, "mismatched types %q and %q for %s", lt.String(), rt.String(), e.Op.Kind.Symbol()).
+		WithLabel(e.Left.ExprNode().Span, "this is of type %q", lt.String()).
+		WithLabel(e.Right.ExprNode().Span, "this is of type %q", rt.String())
+}
+
+func (a *SemanticAnalyzer) analyzeAssignmentExpr(e *ast.Assignment) {
+	a.analyzeExpression(e.Target)
+	a.analyzeExpression(e.Value)
+
+	if !a.checkPlace(e.Target) {
+		return
+	}
+
+	tt, vt := e.Target.ExprNode().Type, e.Value.ExprNode().Type
+	if !isResolved(tt) || !isResolved(vt) {
+		return
+	}
+
+	if op, ok := e.Op.Kind.CompoundOperator(); ok {
+		// 'a += b' follows the rules of 'a + b', and the result must fit back in a
+		bin := &ast.Binary{
+			ExprBase: ast.ExprBase{Span: e.Span},
+			Op:       e.Op,
+			Left:     e.Target,
+			Right:    e.Value,
+		}
+		a.unifyOperands(bin, op)
+		a.checkBinaryOperands(bin, op)
+		if !isResolved(bin.Type) {
+			return
+		}
+		vt = bin.Type
+	} else {
+		a.convertUntyped(e.Value, tt)
+		vt = e.Value.ExprNode().Type
+	}
+
+	if !types.AssignableTo(vt, tt) {
+		a.addErrorExpr(diag.ErrTypeMismatch, e.Value.ExprNode(), "cannot assign a value of type %q to a place of type %q", vt.String(), tt.String()).
+			WithLabel(e.Target.ExprNode().Span, "this is of type %q", tt.String())
+		return
+	}
+
+	e.Type = tt
+}
+
+// Reports whether the expression denotes a place that can be assigned to, reporting an error otherwise
+func (a *SemanticAnalyzer) checkPlace(target ast.Expression) bool {
+	switch t := target.(type) {
+	case *ast.Identifier:
+		switch decl := t.Decl.(type) {
+		case nil:
+			// Undefined, already reported
+			return false
+
+		case *ast.VariableDeclaration:
+			if decl.Mutable {
+				return true
+			}
+
+			a.addErrorExpr(diag.ErrAssignToImmutable, &t.ExprBase, "cannot assign to %s, which is a let binding", t.Ident.Identifier).
+				WithLabel(decl.Name.Span, "%s declared with 'let' here", t.Ident.Identifier).
+				WithFix(letKeyword(decl), "var", "declare it with 'var' to make it mutable")
+			return false
+
+		case *ast.ArgPair:
+			a.addErrorExpr(diag.ErrAssignToImmutable, &t.ExprBase, "cannot assign to parameter %s", t.Ident.Identifier).
+				WithLabel(decl.Name.Span, "%s declared as a parameter here", t.Ident.Identifier).
+				WithHelp("copy it into a local declared with 'var'")
+			return false
+
+		case *ast.FunctionDeclaration:
+			a.addErrorExpr(diag.ErrAssignToFunction, &t.ExprBase, "cannot assign to function %s", t.Ident.Identifier).
+				WithLabel(decl.Name.Span, "%s declared as a function here", t.Ident.Identifier)
+			return false
+		}
+
+	case *ast.Unary:
+		if t.Op.Kind == token.TokOpStar {
+			return true
+		}
+
+	case *ast.Indexed:
+		if isArray(t.Indexee.ExprNode().Type) {
+			return a.checkPlace(t.Indexee)
+		}
+		return true
+	}
+
+	a.addErrorExpr(diag.ErrNotAssignable, target.ExprNode(), "cannot assign to this expression").
+		WithNote("only mutable variables, dereferenced pointers and indexed elements can be assigned to")
+	return false
+}
+
+// Checks that the address of an operand can be taken, as done by '&' and by slicing an array,
+// reporting errors at the operation. A pointer can be written through, so the operand must be
+// a place that could be assigned to. The verb describes the operation in messages.
+func (a *SemanticAnalyzer) checkAddressable(operand ast.Expression, e *ast.ExprBase, verb string) bool {
+	switch t := operand.(type) {
+	case *ast.Identifier:
+		name := t.Ident.Identifier
+
+		switch decl := t.Decl.(type) {
+		case nil:
+			return false
+
+		case *ast.VariableDeclaration:
+			if decl.Mutable {
+				return true
+			}
+			a.addErrorExpr(diag.ErrNotAddressable, e, "cannot %s %s, which is a let binding", verb, name).
+				WithLabel(decl.Name.Span, "%s declared with 'let' here", name).
+				WithFix(letKeyword(decl), "var", "declare it with 'var' to make it mutable")
+			return false
+
+		case *ast.ArgPair:
+			a.addErrorExpr(diag.ErrNotAddressable, e, "cannot %s parameter %s", verb, name).
+				WithLabel(decl.Name.Span, "%s declared as a parameter here", name).
+				WithHelp("copy it into a local declared with 'var'")
+			return false
+
+		case *ast.FunctionDeclaration:
+			a.addErrorExpr(diag.ErrNotAddressable, e, "cannot %s function %s", verb, name).
+				WithNote("a function name already evaluates to a pointer to the function")
+			return false
+		}
+
+	case *ast.Unary:
+		if t.Op.Kind == token.TokOpStar {
+			return true
+		}
+
+	case *ast.Indexed:
+		// Elements of an array are stored where the array is, those of a slice elsewhere
+		if isArray(t.Indexee.ExprNode().Type) {
+			return a.checkAddressable(t.Indexee, e, verb)
+		}
+		return true
+	}
+
+	a.addErrorExpr(diag.ErrNotAddressable, e, "cannot %s this expression", verb).
+		WithNote("only mutable variables, dereferenced pointers and indexed elements have an address")
+	return false
+}
+
+// Returns the span of the 'let' keyword a declaration starts with
+func letKeyword(decl *ast.VariableDeclaration) token.Span {
+	kw := decl.Span
+	kw.End = kw.Start
+	kw.End.Offset += len("let")
+	kw.End.Column += len("let")
+	return kw
 }
 
 func (a *SemanticAnalyzer) analyzeCallExpr(e *ast.Call) {
-	a.addErrorExpr(&e.ExprBase, "call expression not supported yet")
+	if id, ok := e.Callee.(*ast.Identifier); ok {
+		if sym, ok := a.currentScope.getSymbol(id.Ident.Identifier); ok && sym.getSymbolKind() == symbolBuiltin {
+			a.analyzeBuiltinCall(e, sym.(*builtinSymbol))
+			return
+		}
+	}
+
+	a.analyzeExpression(e.Callee)
+	for _, arg := range e.Args {
+		a.analyzeExpression(arg)
+	}
+
+	ct := e.Callee.ExprNode().Type
+	if !isResolved(ct) {
+		return
+	}
+
+	ft, ok := ct.(*types.Function)
+	if !ok {
+		a.addErrorExpr(diag.ErrNotCallable, e.Callee.ExprNode(), "cannot call a value of type %q", ct.String())
+		return
+	}
+
+	// Parameter names are only known when calling a declared function directly
+	var decl *ast.FunctionDeclaration
+	if id, ok := e.Callee.(*ast.Identifier); ok {
+		decl, _ = id.Decl.(*ast.FunctionDeclaration)
+	}
+
+	params := ft.Params()
+	if len(e.Args) != len(params) {
+		err := a.addErrorExpr(diag.ErrArgumentCount, &e.ExprBase, "expected %d arguments, found %d", len(params), len(e.Args))
+		if decl != nil {
+			err.WithLabel(decl.Name.Span, "%s declared as %s here", decl.Name.Identifier, ft.String())
+		}
+	} else {
+		for i, arg := range e.Args {
+			a.convertUntyped(arg, params[i])
+			at := arg.ExprNode().Type
+			if !isResolved(at) || !isResolved(params[i]) || types.AssignableTo(at, params[i]) {
+				continue
+			}
+
+			err := a.addErrorExpr(diag.ErrTypeMismatch, arg.ExprNode(), "argument of type %q cannot be used as a parameter of type %q", at.String(), params[i].String())
+			if decl != nil {
+				err.WithLabel(decl.Args[i].Name.Span, "parameter %s declared here", decl.Args[i].Name.Identifier)
+			}
+		}
+	}
+
+	// The call has a type even with invalid arguments, avoiding cascading errors
+	e.Type = ft.Result()
 }
 
-func (a *SemanticAnalyzer) analyzeIndexedExpr(e *ast.Indexed) {
-	a.addErrorExpr(&e.ExprBase, "index expression not supported yet")
+func (a *SemanticAnalyzer) analyzeCastExpr(e *ast.Cast) {
+	a.analyzeExpression(e.Value)
+
+	// The cast has its type even when invalid, avoiding cascading errors
+	target := a.resolveType(e.Target)
+	e.Type = target
+	if !isResolved(target) {
+		return
+	}
+
+	// A constant that fits the target is converted exactly, others are
+	// converted from their default type, truncating or wrapping like any value
+	if c := e.Value.ExprNode().Const; c != nil && types.IsNumeric(target) {
+		if _, ok := representable(c, target); ok {
+			a.convertUntyped(e.Value, target)
+		}
+	}
+	if e.Value.ExprNode().Type == types.UntypedNull {
+		a.convertUntyped(e.Value, target)
+	}
+	a.defaultUntyped(e.Value)
+
+	vt := e.Value.ExprNode().Type
+	if !isResolved(vt) || types.ClassifyCast(vt, target) != types.CastInvalid {
+		return
+	}
+
+	err := a.addErrorExpr(diag.ErrInvalidCast, &e.ExprBase, "cannot cast a value of type %q to %q", vt.String(), target.String()).
+		WithLabel(e.Value.ExprNode().Span, "this is of type %q", vt.String())
+
+	switch {
+	case types.IsBool(target) && types.IsNumeric(vt):
+		err.WithHelp("compare it against zero instead")
+	case types.IsBool(target) && types.IsPointer(vt):
+		err.WithHelp("compare it against null instead")
+	case types.IsPointer(target) && types.IsPointer(vt):
+		err.WithHelp("cast it to ptr first, as in 'p as ptr as %s'", target.String())
+	default:
+		err.WithNote("casts convert between numbers, and between integers and pointers")
+	}
 }
@@ -3,8 +3,9 @@ package sema
 import "fmt"
 
 type scope struct {
-	symbols map[string]symbol
-	parent  *scope
+	symbols  map[string]symbol
+	parent   *scope
+	universe bool // Holds the predeclared symbols, which declarations can shadow
 }
 
 func newScope(parent *scope) *scope {
@@ -19,14 +20,8 @@ func (s *scope) newChildScope() *scope {
 }
 
 func (s *scope) isSymbolPresent(name string) bool {
-	_, ok := s.symbols[name]
-	if !ok {
-		if s.parent == nil {
-			return false
-		}
-		return s.parent.isSymbolPresent(name)
-	}
-	return true
+	_, ok := s.getDeclared(name)
+	return ok
 }
 
 func (s *scope) addSymbol(name string, sym symbol) error {
@@ -47,3 +42,19 @@ func (s *scope) getSymbol(name string) (symbol, bool) {
 	}
 	return val, true
 }
+
+// Like getSymbol, but skips the universe scope. Finds the declarations a new one would clash with.
+func (s *scope) getDeclared(name string) (symbol, bool) {
+	if s.universe {
+		return nil, false
+	}
+
+	val, ok := s.symbols[name]
+	if !ok {
+		if s.parent == nil {
+			return nil, false
+		}
+		return s.parent.getDeclared(name)
+	}
+	return val, true
+}
@@ -3,6 +3,7 @@ package sema
 import (
 	"fracta/internal/ast"
 	"fracta/internal/diag"
+	"fracta/internal/token"
 )
 
 type SemanticAnalyzer struct {
@@ -14,4 +15,11 @@ type SemanticAnalyzer struct {
 	currentScope    *scope
 	currentFile     string
 	currentFunction *ast.FunctionDeclaration
+	loops           []loopContext // Loops enclosing the current statement, innermost last
+}
+
+// A loop being analyzed, which break and continue may target
+type loopContext struct {
+	label *token.Token
+	stmt  ast.Statement
 }
@@ -1,27 +1,36 @@
 package sema
 
-import "fracta/internal/ast"
+import (
+	"fracta/internal/ast"
+	"fracta/internal/token"
+	"fracta/internal/types"
+)
 
 type symbolKind int
 
 const (
 	symbolFunction symbolKind = iota
 	symbolType
+	symbolVariable
+	symbolBuiltin
+	symbolConstant
 )
 
 type symbol interface {
 	getSymbolKind() symbolKind
 	getSymbolBase() *symbolBase
-	getExprType() ast.Type
+	getExprType() types.Type
 }
 
 type symbolBase struct {
-	pkg string
+	pkg  string
+	span token.Span  // Where the symbol was declared
+	decl ast.ASTNode // Declaration node of the symbol
 }
 
 type functionSymbol struct {
 	symbolBase
-	fType *ast.FunctionType
+	fType *types.Function
 }
 
 func (functionSymbol) getSymbolKind() symbolKind {
@@ -32,6 +41,61 @@ func (s *functionSymbol) getSymbolBase() *symbolBase {
 	return &s.symbolBase
 }
 
-func (s *functionSymbol) getExprType() ast.Type {
+func (s *functionSymbol) getExprType() types.Type {
 	return s.fType
 }
+
+type variableSymbol struct {
+	symbolBase
+	vType   types.Type
+	mutable bool
+}
+
+func (variableSymbol) getSymbolKind() symbolKind {
+	return symbolVariable
+}
+
+func (s *variableSymbol) getSymbolBase() *symbolBase {
+	return &s.symbolBase
+}
+
+func (s *variableSymbol) getExprType() types.Type {
+	return s.vType
+}
+
+// A function provided by the compiler. Calls to it are checked and generated one by one,
+// so it has no type of its own and cannot be used as a value.
+type builtinSymbol struct {
+	symbolBase
+	name string
+}
+
+func (builtinSymbol) getSymbolKind() symbolKind {
+	return symbolBuiltin
+}
+
+func (s *builtinSymbol) getSymbolBase() *symbolBase {
+	return &s.symbolBase
+}
+
+func (s *builtinSymbol) getExprType() types.Type {
+	return types.Invalid
+}
+
+// A predeclared constant like inf. It is declared by the literal giving its value.
+type constantSymbol struct {
+	symbolBase
+	cType types.Type
+}
+
+func (constantSymbol) getSymbolKind() symbolKind {
+	return symbolConstant
+}
+
+func (s *constantSymbol) getSymbolBase() *symbolBase {
+	return &s.symbolBase
+}
+
+func (s *constantSymbol) getExprType() types.Type {
+	return s.cType
+}
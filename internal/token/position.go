@@ -0,0 +1,52 @@
+package token
+
+import "fmt"
+
+// Represents a single point within a source file
+type Position struct {
+	Offset int // Byte offset from the start of the file
+	Line   int // Line number, starting at 1
+	Column int // Column number, in runes, starting at 1
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Represents a range of source text, from Start up to (but not including) End
+type Span struct {
+	File  string
+	Start Position
+	End   Position
+}
+
+// Reports whether the span points to an actual source location
+func (s Span) IsValid() bool {
+	return s.Start.Line > 0
+}
+
+// Returns the smallest span covering both s and other
+func (s Span) To(other Span) Span {
+	if !s.IsValid() {
+		return other
+	}
+	if !other.IsValid() {
+		return s
+	}
+
+	out := s
+	if other.Start.Offset < out.Start.Offset {
+		out.Start = other.Start
+	}
+	if other.End.Offset > out.End.Offset {
+		out.End = other.End
+	}
+	return out
+}
+
+func (s Span) String() string {
+	if !s.IsValid() {
+		return s.File
+	}
+	return fmt.Sprintf("%s:%s", s.File, s.Start.String())
+}
@@ -0,0 +1,98 @@
+package token
+
+var symbols = map[TokenType]string{
+	TokOpPlus:  "+",
+	TokOpMinus: "-",
+	TokOpStar:  "*",
+	TokOpSlash: "/",
+	TokOpMod:   "%",
+
+	TokOpAssign:           "=",
+	TokOpPlusAssign:       "+=",
+	TokOpMinusAssign:      "-=",
+	TokOpStarAssign:       "*=",
+	TokOpSlashAssign:      "/=",
+	TokOpModAssign:        "%=",
+	TokOpAmpersandAssign:  "&=",
+	TokOpPipeAssign:       "|=",
+	TokOpCaretAssign:      "^=",
+	TokOpShiftLeftAssign:  "<<=",
+	TokOpShiftRightAssign: ">>=",
+
+	TokOpEq:           "==",
+	TokOpNotEq:        "!=",
+	TokOpLessThan:     "<",
+	TokOpGreaterThan:  ">",
+	TokOpLessEqual:    "<=",
+	TokOpGreaterEqual: ">=",
+
+	TokOpLogicalAnd: "&&",
+	TokOpLogicalOr:  "||",
+	TokOpBang:       "!",
+
+	TokOpAmpersand:  "&",
+	TokOpPipe:       "|",
+	TokOpCaret:      "^",
+	TokOpTilde:      "~",
+	TokOpShiftLeft:  "<<",
+	TokOpShiftRight: ">>",
+
+	TokOpenParen:    "(",
+	TokCloseParen:   ")",
+	TokOpenSquare:   "[",
+	TokCloseSquare:  "]",
+	TokOpenBracket:  "{",
+	TokCloseBracket: "}",
+
+	TokOpDot:         ".",
+	TokOpColon:       ":",
+	TokOpDoubleColon: "::",
+	TokOpComma:       ",",
+
+	TokSemicolon: ";",
+
+	TokKwFunc:     "func",
+	TokKwReturn:   "return",
+	TokKwTrue:     "true",
+	TokKwFalse:    "false",
+	TokKwNull:     "null",
+	TokKwVar:      "var",
+	TokKwLet:      "let",
+	TokKwIf:       "if",
+	TokKwElse:     "else",
+	TokKwWhile:    "while",
+	TokKwFor:      "for",
+	TokKwLoop:     "loop",
+	TokKwBreak:    "break",
+	TokKwContinue: "continue",
+	TokKwAs:       "as",
+}
+
+var compoundOperators = map[TokenType]TokenType{
+	TokOpPlusAssign:       TokOpPlus,
+	TokOpMinusAssign:      TokOpMinus,
+	TokOpStarAssign:       TokOpStar,
+	TokOpSlashAssign:      TokOpSlash,
+	TokOpModAssign:        TokOpMod,
+	TokOpAmpersandAssign:  TokOpAmpersand,
+	TokOpPipeAssign:       TokOpPipe,
+	TokOpCaretAssign:      TokOpCaret,
+	TokOpShiftLeftAssign:  TokOpShiftLeft,
+	TokOpShiftRightAssign: TokOpShiftRight,
+}
+
+// Returns the binary operator a compound assignment applies, like '+' for '+='.
+// Returns false for any other token.
+func (t TokenType) CompoundOperator() (TokenType, bool) {
+	op, ok := compoundOperators[t]
+	return op, ok
+}
+
+// Returns how the token is written in source, quoted, like '+' or 'return'.
+// Tokens without a fixed spelling, like literals, give their kind name instead.
+func (t TokenType) Symbol() string {
+	if s, ok := symbols[t]; ok {
+		return "'" + s + "'"
+	}
+	return t.String()[3:]
+}
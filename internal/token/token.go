@@ -27,7 +27,10 @@ const (
 	TokF32 // 32 bit floating point literal
 	TokF64 // 64 bit floating point literal
 
-	TokChar   // 8 bit character literal
+	TokInt   // Integer literal without a suffix, holding an exact constant.Value
+	TokFloat // Float literal without a suffix, holding an exact constant.Value
+
+	TokChar   // Character literal, holding a code point
 	TokString // String literal
 
 	TokIdentifier // Any identifier
@@ -38,7 +41,17 @@ const (
 	TokOpSlash // Operator '/'
 	TokOpMod   // Operator '%'
 
-	TokOpAssign // Operator '='
+	TokOpAssign           // Operator '='
+	TokOpPlusAssign       // Operator '+='
+	TokOpMinusAssign      // Operator '-='
+	TokOpStarAssign       // Operator '*='
+	TokOpSlashAssign      // Operator '/='
+	TokOpModAssign        // Operator '%='
+	TokOpAmpersandAssign  // Operator '&='
+	TokOpPipeAssign       // Operator '|='
+	TokOpCaretAssign      // Operator '^='
+	TokOpShiftLeftAssign  // Operator '<<='
+	TokOpShiftRightAssign // Operator '>>='
 
 	TokOpEq           // Operator '=='
 	TokOpNotEq        // Operator '!='
@@ -47,6 +60,17 @@ const (
 	TokOpLessEqual    // Operator '<='
 	TokOpGreaterEqual // Operator '>='
 
+	TokOpLogicalAnd // Operator '&&'
+	TokOpLogicalOr  // Operator '||'
+	TokOpBang       // Operator '!'
+
+	TokOpAmpersand  // Operator '&'
+	TokOpPipe       // Operator '|'
+	TokOpCaret      // Operator '^'
+	TokOpTilde      // Operator '~'
+	TokOpShiftLeft  // Operator '<<'
+	TokOpShiftRight // Operator '>>'
+
 	TokOpenParen    // Punctuation '('
 	TokCloseParen   // Punctuation ')'
 	TokOpenSquare   // Punctuation '['
@@ -61,8 +85,21 @@ const (
 
 	TokSemicolon // Punctuation ';'
 
-	TokKwFunc   // Keyword 'func'
-	TokKwReturn // Keyword 'return'
+	TokKwFunc     // Keyword 'func'
+	TokKwReturn   // Keyword 'return'
+	TokKwTrue     // Keyword 'true'
+	TokKwFalse    // Keyword 'false'
+	TokKwNull     // Keyword 'null'
+	TokKwVar      // Keyword 'var'
+	TokKwLet      // Keyword 'let'
+	TokKwIf       // Keyword 'if'
+	TokKwElse     // Keyword 'else'
+	TokKwWhile    // Keyword 'while'
+	TokKwFor      // Keyword 'for'
+	TokKwLoop     // Keyword 'loop'
+	TokKwBreak    // Keyword 'break'
+	TokKwContinue // Keyword 'continue'
+	TokKwAs       // Keyword 'as'
 )
 
 // Represents a token from Fracta
@@ -71,14 +108,20 @@ type Token struct {
 	Lexeme     string    // The source string this token was built out of
 	Value      any       // Literal value
 	Identifier string    // Identifier name if any
-	Line       int       // Position within source file
+	Span       Span      // Position within source file
+	Doc        string    // Text of the doc comments right before the token, if any
+
+	// Only filled in trivia mode. Trailing trivia runs up to the end of the line,
+	// everything else before a token is part of its leading trivia.
+	LeadingTrivia  []Trivia
+	TrailingTrivia []Trivia
 }
 
 func (t Token) String() string {
 	name := t.Kind.String()[3:]
 
 	switch t.Kind {
-	case TokI8, TokI16, TokI32, TokI64, TokU8, TokU16, TokU32, TokU64, TokF32, TokF64:
+	case TokI8, TokI16, TokI32, TokI64, TokU8, TokU16, TokU32, TokU64, TokF32, TokF64, TokInt, TokFloat:
 		return fmt.Sprintf("%s(%v)", name, t.Value)
 	case TokChar:
 		return fmt.Sprintf("%s(%s)", name, strconv.QuoteRune(t.Value.(rune)))
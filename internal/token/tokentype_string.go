@@ -21,39 +21,73 @@ func _() {
 	_ = x[TokU64-10]
 	_ = x[TokF32-11]
 	_ = x[TokF64-12]
-	_ = x[TokChar-13]
-	_ = x[TokString-14]
-	_ = x[TokIdentifier-15]
-	_ = x[TokOpPlus-16]
-	_ = x[TokOpMinus-17]
-	_ = x[TokOpStar-18]
-	_ = x[TokOpSlash-19]
-	_ = x[TokOpMod-20]
-	_ = x[TokOpAssign-21]
-	_ = x[TokOpEq-22]
-	_ = x[TokOpNotEq-23]
-	_ = x[TokOpLessThan-24]
-	_ = x[TokOpGreaterThan-25]
-	_ = x[TokOpLessEqual-26]
-	_ = x[TokOpGreaterEqual-27]
-	_ = x[TokOpenParen-28]
-	_ = x[TokCloseParen-29]
-	_ = x[TokOpenSquare-30]
-	_ = x[TokCloseSquare-31]
-	_ = x[TokOpenBracket-32]
-	_ = x[TokCloseBracket-33]
-	_ = x[TokOpDot-34]
-	_ = x[TokOpColon-35]
-	_ = x[TokOpDoubleColon-36]
-	_ = x[TokOpComma-37]
-	_ = x[TokSemicolon-38]
-	_ = x[TokKwFunc-39]
-	_ = x[TokKwReturn-40]
+	_ = x[TokInt-13]
+	_ = x[TokFloat-14]
+	_ = x[TokChar-15]
+	_ = x[TokString-16]
+	_ = x[TokIdentifier-17]
+	_ = x[TokOpPlus-18]
+	_ = x[TokOpMinus-19]
+	_ = x[TokOpStar-20]
+	_ = x[TokOpSlash-21]
+	_ = x[TokOpMod-22]
+	_ = x[TokOpAssign-23]
+	_ = x[TokOpPlusAssign-24]
+	_ = x[TokOpMinusAssign-25]
+	_ = x[TokOpStarAssign-26]
+	_ = x[TokOpSlashAssign-27]
+	_ = x[TokOpModAssign-28]
+	_ = x[TokOpAmpersandAssign-29]
+	_ = x[TokOpPipeAssign-30]
+	_ = x[TokOpCaretAssign-31]
+	_ = x[TokOpShiftLeftAssign-32]
+	_ = x[TokOpShiftRightAssign-33]
+	_ = x[TokOpEq-34]
+	_ = x[TokOpNotEq-35]
+	_ = x[TokOpLessThan-36]
+	_ = x[TokOpGreaterThan-37]
+	_ = x[TokOpLessEqual-38]
+	_ = x[TokOpGreaterEqual-39]
+	_ = x[TokOpLogicalAnd-40]
+	_ = x[TokOpLogicalOr-41]
+	_ = x[TokOpBang-42]
+	_ = x[TokOpAmpersand-43]
+	_ = x[TokOpPipe-44]
+	_ = x[TokOpCaret-45]
+	_ = x[TokOpTilde-46]
+	_ = x[TokOpShiftLeft-47]
+	_ = x[TokOpShiftRight-48]
+	_ = x[TokOpenParen-49]
+	_ = x[TokCloseParen-50]
+	_ = x[TokOpenSquare-51]
+	_ = x[TokCloseSquare-52]
+	_ = x[TokOpenBracket-53]
+	_ = x[TokCloseBracket-54]
+	_ = x[TokOpDot-55]
+	_ = x[TokOpColon-56]
+	_ = x[TokOpDoubleColon-57]
+	_ = x[TokOpComma-58]
+	_ = x[TokSemicolon-59]
+	_ = x[TokKwFunc-60]
+	_ = x[TokKwReturn-61]
+	_ = x[TokKwTrue-62]
+	_ = x[TokKwFalse-63]
+	_ = x[TokKwNull-64]
+	_ = x[TokKwVar-65]
+	_ = x[TokKwLet-66]
+	_ = x[TokKwIf-67]
+	_ = x[TokKwElse-68]
+	_ = x[TokKwWhile-69]
+	_ = x[TokKwFor-70]
+	_ = x[TokKwLoop-71]
+	_ = x[TokKwBreak-72]
+	_ = x[TokKwContinue-73]
+	_ = x[TokKwAs-74]
 }
 
-const _TokenType_name = "TokNoneTokErrorTokEndOfFileTokI8TokI16TokI32TokI64TokU8TokU16TokU32TokU64TokF32TokF64TokCharTokStringTokIdentifierTokOpPlusTokOpMinusTokOpStarTokOpSlashTokOpModTokOpAssignTokOpEqTokOpNotEqTokOpLessThanTokOpGreaterThanTokOpLessEqualTokOpGreaterEqualTokOpenParenTokCloseParenTokOpenSquareTokCloseSquareTokOpenBracketTokCloseBracketTokOpDotTokOpColonTokOpDoubleColonTokOpCommaTokSemicolonTokKwFuncTokKwReturn"
+const _TokenType_name = "TokNoneTokErrorTokEndOfFileTokI8TokI16TokI32TokI64TokU8TokU16TokU32TokU64TokF32TokF64TokIntTokFloatTokCharTokStringTokIdentifierTokOpPlusTokOpMinusTokOpStarTokOpSlashTokOpModTokOpAssignTokOpPlusAssignTokOpMinusAssignTokOpStarAssignTokOpSlashAssignTokOpModAssignTokOpAmpersandAssignTokOpPipeAssignTokOpCaretAssignTokOpShiftLeftAssignTokOpShiftRightAssignTokOpEqTokOpNotEqTokOpLessThanTokOpGreaterThanTokOpLessEqualTokOpGreaterEqualTokOpLogicalAndTokOpLogicalOrTokOpBangTokOpAmpersandTokOpPipeTokOpCaretTokOpTildeTokOpShiftLeftTokOpShiftRightTokOpenParenTokCloseParenTokOpenSquareTokCloseSquareTokOpenBracketTokCloseBracketTokOpDotTokOpColonTokOpDoubleColonTokOpCommaTokSemicolonTokKwFuncTokKwReturnTokKwTrueTokKwFalseTokKwNullTokKwVarTokKwLetTokKwIfTokKwElseTokKwWhileTokKwForTokKwLoopTokKwBreakTokKwContinueTokKwAs"
 
-var _TokenType_index = [...]uint16{0, 7, 15, 27, 32, 38, 44, 50, 55, 61, 67, 73, 79, 85, 92, 101, 114, 123, 133, 142, 152, 160, 171, 178, 188, 201, 217, 231, 248, 260, 273, 286, 300, 314, 329, 337, 347, 363, 373, 385, 394, 405}
+var _TokenType_index = [...]uint16{0, 7, 15, 27, 32, 38, 44, 50, 55, 61, 67, 73, 79, 85, 91, 99, 106, 115, 128, 137, 147, 156, 166, 174, 185, 200, 216, 231, 247, 261, 281, 296, 312, 332, 353, 360, 370, 383, 399, 413, 430, 445, 459, 468, 482, 491, 501, 511, 525, 540, 552, 565, 578, 592, 606, 621, 629, 639, 655, 665, 677, 686, 697, 706, 716, 725, 733, 741, 748, 757, 767, 775, 784, 794, 807, 814}
 
 func (i TokenType) String() string {
 	idx := int(i) - 0
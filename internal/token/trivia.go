@@ -0,0 +1,34 @@
+package token
+
+import "strings"
+
+// Represents the kind of a piece of trivia
+type TriviaKind byte
+
+const (
+	TriviaWhitespace   TriviaKind = iota // Run of spaces, tabs and carriage returns
+	TriviaNewline                        // Single line feed
+	TriviaLineComment                    // '//' comment, without the line feed ending it
+	TriviaBlockComment                   // '/* */' comment
+)
+
+// Source text that carries no meaning for the parser, only produced in trivia mode
+type Trivia struct {
+	Kind TriviaKind
+	Text string // Exact source text
+	Span Span
+}
+
+// Returns the exact source text of the token, trivia included.
+// Concatenating it for every token of a stream in trivia mode gives back the original source.
+func (t Token) FullText() string {
+	var sb strings.Builder
+	for _, v := range t.LeadingTrivia {
+		sb.WriteString(v.Text)
+	}
+	sb.WriteString(t.Lexeme)
+	for _, v := range t.TrailingTrivia {
+		sb.WriteString(v.Text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package types
+
+// Operation performed by an 'as' cast
+type CastKind int
+
+const (
+	CastInvalid     CastKind = iota // Not allowed
+	CastNone                        // Same representation, the value is used as is
+	CastIntResize                   // Between integers, char and bool included: truncation or extension
+	CastIntToFloat                  // Integer to float, rounding to the nearest value
+	CastFloatToInt                  // Float to integer, truncating toward zero
+	CastFloatResize                 // Between f32 and f64
+	CastIntToPtr                    // Integer to pointer, the integer is an address
+	CastPtrToInt                    // Pointer to integer, giving the address
+	CastPtrToPtr                    // Between ptr and a typed pointer, or from null, keeping the address
+)
+
+// Returns how a value of type from is converted to type to by a cast.
+//
+//	from \ to   int   float  bool  char  pointer
+//	int         yes   yes    -     yes   yes
+//	float       yes   yes    -     -     -
+//	bool        yes   -      yes   -     -
+//	char        yes   -      -     yes   -
+//	pointer     yes   -      -     -     ptr
+//	null        -     -      -     -     yes
+//
+// A typed pointer only converts to and from ptr, reinterpreting a *T as a *U goes through ptr.
+func ClassifyCast(from, to Type) CastKind {
+	switch {
+	case IdenticalUnderlying(from, to):
+		return CastNone
+	case IsPointer(to) && from == UntypedNull:
+		return CastPtrToPtr
+	case IsPointer(to) && IsPointer(from) && (Underlying(from) == Ptr || Underlying(to) == Ptr):
+		return CastPtrToPtr
+	case IsInteger(to) && (IsInteger(from) || IsChar(from) || IsBool(from)):
+		return CastIntResize
+	case IsChar(to) && IsInteger(from):
+		return CastIntResize
+	case IsFloat(to) && IsInteger(from):
+		return CastIntToFloat
+	case IsInteger(to) && IsFloat(from):
+		return CastFloatToInt
+	case IsFloat(to) && IsFloat(from):
+		return CastFloatResize
+	case IsPointer(to) && IsInteger(from):
+		return CastIntToPtr
+	case IsInteger(to) && IsPointer(from):
+		return CastPtrToInt
+	default:
+		return CastInvalid
+	}
+}
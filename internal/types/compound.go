@@ -0,0 +1,238 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Canonical instances of every composite type built so far
+var interned = struct {
+	sync.Mutex
+	pointers  map[Type]*Pointer
+	arrays    map[arrayKey]*Array
+	slices    map[Type]*Slice
+	functions map[string]*Function
+	structs   map[string]*Struct
+}{
+	pointers:  map[Type]*Pointer{},
+	arrays:    map[arrayKey]*Array{},
+	slices:    map[Type]*Slice{},
+	functions: map[string]*Function{},
+	structs:   map[string]*Struct{},
+}
+
+// Pointer to a value of a known type, unlike ptr
+type Pointer struct {
+	elem Type
+}
+
+func (*Pointer) typ() {}
+
+func (p *Pointer) String() string { return "*" + p.elem.String() }
+
+func (p *Pointer) Elem() Type { return p.elem }
+
+// Returns the pointer type to elem
+func NewPointer(elem Type) *Pointer {
+	interned.Lock()
+	defer interned.Unlock()
+
+	if p, ok := interned.pointers[elem]; ok {
+		return p
+	}
+	p := &Pointer{elem: elem}
+	interned.pointers[elem] = p
+	return p
+}
+
+// View of a run of elements stored elsewhere, a pointer to the first one and a length
+type Slice struct {
+	elem Type
+}
+
+func (*Slice) typ() {}
+
+func (s *Slice) String() string { return "[]" + s.elem.String() }
+
+func (s *Slice) Elem() Type { return s.elem }
+
+// Returns the slice type of elem
+func NewSlice(elem Type) *Slice {
+	interned.Lock()
+	defer interned.Unlock()
+
+	if s, ok := interned.slices[elem]; ok {
+		return s
+	}
+	s := &Slice{elem: elem}
+	interned.slices[elem] = s
+	return s
+}
+
+type arrayKey struct {
+	elem Type
+	len  int64
+}
+
+// Fixed size array, stored inline
+type Array struct {
+	elem Type
+	len  int64
+}
+
+func (*Array) typ() {}
+
+func (a *Array) String() string { return fmt.Sprintf("[%d]%s", a.len, a.elem.String()) }
+
+func (a *Array) Elem() Type { return a.elem }
+
+func (a *Array) Len() int64 { return a.len }
+
+// Returns the type of arrays of n elements of type elem
+func NewArray(elem Type, n int64) *Array {
+	interned.Lock()
+	defer interned.Unlock()
+
+	key := arrayKey{elem, n}
+	if a, ok := interned.arrays[key]; ok {
+		return a
+	}
+	a := &Array{elem: elem, len: n}
+	interned.arrays[key] = a
+	return a
+}
+
+// Signature of a function. The result is Void for functions without a return type.
+type Function struct {
+	params []Type
+	result Type
+}
+
+func (*Function) typ() {}
+
+func (f *Function) String() string {
+	s := strings.Builder{}
+	_, _ = s.WriteString("func(")
+
+	for i, p := range f.params {
+		if i != 0 {
+			_, _ = s.WriteString(", ")
+		}
+		_, _ = s.WriteString(p.String())
+	}
+
+	_, _ = s.WriteString(")")
+	if f.result != Void {
+		_, _ = fmt.Fprintf(&s, " %s", f.result.String())
+	}
+
+	return s.String()
+}
+
+func (f *Function) Params() []Type { return f.params }
+
+func (f *Function) Result() Type { return f.result }
+
+// Returns the type of functions with the given parameters and result, Void for none
+func NewFunction(params []Type, result Type) *Function {
+	interned.Lock()
+	defer interned.Unlock()
+
+	key := identityKey(append([]Type{result}, params...))
+	if f, ok := interned.functions[key]; ok {
+		return f
+	}
+	f := &Function{params: append([]Type(nil), params...), result: result}
+	interned.functions[key] = f
+	return f
+}
+
+type Field struct {
+	Name string
+	Type Type
+}
+
+// Structure type, two structures are the same type when their fields are
+type Struct struct {
+	fields []Field
+}
+
+func (*Struct) typ() {}
+
+func (s *Struct) String() string {
+	b := strings.Builder{}
+	_, _ = b.WriteString("struct {")
+
+	for i, f := range s.fields {
+		if i != 0 {
+			_, _ = b.WriteString(";")
+		}
+		_, _ = fmt.Fprintf(&b, " %s %s", f.Name, f.Type.String())
+	}
+
+	_, _ = b.WriteString(" }")
+	return b.String()
+}
+
+func (s *Struct) Fields() []Field { return s.fields }
+
+// Returns the structure type with the given fields, in order
+func NewStruct(fields []Field) *Struct {
+	interned.Lock()
+	defer interned.Unlock()
+
+	types := make([]Type, 0, len(fields))
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		types = append(types, f.Type)
+		names = append(names, f.Name)
+	}
+
+	key := strings.Join(names, ",") + "|" + identityKey(types)
+	if s, ok := interned.structs[key]; ok {
+		return s
+	}
+	s := &Struct{fields: append([]Field(nil), fields...)}
+	interned.structs[key] = s
+	return s
+}
+
+// Type declared with a name. Every call to NewNamed creates a distinct type,
+// even with the same name and underlying type.
+type Named struct {
+	name       string
+	underlying Type
+}
+
+func (*Named) typ() {}
+
+func (n *Named) String() string { return n.name }
+
+func (n *Named) Name() string { return n.name }
+
+// Returns the type the named type is defined as, which is never a named type itself
+func (n *Named) Underlying() Type { return n.underlying }
+
+// Creates a new named type. The underlying type can be set later with SetUnderlying,
+// for types that refer to themselves.
+func NewNamed(name string, underlying Type) *Named {
+	n := &Named{name: name}
+	if underlying != nil {
+		n.SetUnderlying(underlying)
+	}
+	return n
+}
+
+func (n *Named) SetUnderlying(t Type) {
+	n.underlying = Underlying(t)
+}
+
+// Returns a key unique to the list of canonical types
+func identityKey(ts []Type) string {
+	b := strings.Builder{}
+	for _, t := range ts {
+		_, _ = fmt.Fprintf(&b, "%p,", t)
+	}
+	return b.String()
+}
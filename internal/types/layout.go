@@ -0,0 +1,50 @@
+package types
+
+// Size of addresses on the supported targets, which are all 64 bits
+const PointerSize = 8
+
+// Returns the size of values of the type in bytes, padding included
+func Size(t Type) int64 {
+	switch t := Underlying(t).(type) {
+	case *Basic:
+		return t.size
+	case *Pointer, *Function:
+		return PointerSize
+	case *Slice:
+		return PointerSize + Size(I64)
+	case *Array:
+		return t.len * Size(t.elem)
+	case *Struct:
+		var offset int64
+		for _, f := range t.fields {
+			offset = alignTo(offset, Align(f.Type)) + Size(f.Type)
+		}
+		return alignTo(offset, Align(t))
+	default:
+		return 0
+	}
+}
+
+// Returns the alignment of values of the type in bytes
+func Align(t Type) int64 {
+	switch t := Underlying(t).(type) {
+	case *Basic:
+		return max(t.size, 1)
+	case *Pointer, *Function, *Slice:
+		return PointerSize
+	case *Array:
+		return Align(t.elem)
+	case *Struct:
+		var a int64 = 1
+		for _, f := range t.fields {
+			a = max(a, Align(f.Type))
+		}
+		return a
+	default:
+		return 1
+	}
+}
+
+func alignTo(offset, align int64) int64 {
+	return (offset + align - 1) / align * align
+}
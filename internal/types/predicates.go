@@ -0,0 +1,113 @@
+package types
+
+// Returns the type a type is defined as: itself unless it is a named type
+func Underlying(t Type) Type {
+	if n, ok := t.(*Named); ok {
+		return n.underlying
+	}
+	return t
+}
+
+func basicInfoOf(t Type) (basicInfo, bool) {
+	if b, ok := Underlying(t).(*Basic); ok {
+		return b.info, true
+	}
+	return 0, false
+}
+
+func isBasic(t Type, kinds ...BasicKind) bool {
+	b, ok := Underlying(t).(*Basic)
+	if !ok {
+		return false
+	}
+	for _, k := range kinds {
+		if b.kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// The predicates below hold for typed values only, untyped constants only satisfy IsUntyped
+
+func IsInteger(t Type) bool {
+	info, ok := basicInfoOf(t)
+	return ok && info&infoInteger != 0 && info&infoUntyped == 0
+}
+
+func IsSigned(t Type) bool {
+	info, ok := basicInfoOf(t)
+	return ok && info&infoInteger != 0 && info&(infoUnsigned|infoUntyped) == 0
+}
+
+func IsUnsigned(t Type) bool {
+	info, ok := basicInfoOf(t)
+	return ok && info&infoUnsigned != 0
+}
+
+func IsFloat(t Type) bool {
+	info, ok := basicInfoOf(t)
+	return ok && info&infoFloat != 0 && info&infoUntyped == 0
+}
+
+func IsNumeric(t Type) bool {
+	return IsInteger(t) || IsFloat(t)
+}
+
+func IsBool(t Type) bool {
+	return isBasic(t, KindBool)
+}
+
+func IsChar(t Type) bool {
+	return isBasic(t, KindChar)
+}
+
+// Reports whether the type is ptr or a typed pointer
+func IsPointer(t Type) bool {
+	if _, ok := Underlying(t).(*Pointer); ok {
+		return true
+	}
+	return isBasic(t, KindPtr)
+}
+
+func IsUntyped(t Type) bool {
+	info, ok := basicInfoOf(t)
+	return ok && info&infoUntyped != 0
+}
+
+// Reports whether values of the type can be compared with == and !=
+func IsComparable(t Type) bool {
+	return IsNumeric(t) || IsPointer(t) || isBasic(t, KindBool, KindChar, KindUntypedNull)
+}
+
+// Reports whether two types are the same. Canonical types make this ==, but named types
+// are only identical to themselves even when another one has the same structure.
+func Identical(x, y Type) bool {
+	return x == y
+}
+
+// Reports whether two types have the same structure once names are looked through,
+// as needed to convert between a named type and its definition
+func IdenticalUnderlying(x, y Type) bool {
+	return Underlying(x) == Underlying(y)
+}
+
+// Reports whether a value of type v can be used where a value of type t is expected
+func AssignableTo(v, t Type) bool {
+	if v == UntypedNull {
+		return IsPointer(t) || t == UntypedNull
+	}
+	return Identical(v, t)
+}
+
+// Returns the type an untyped constant takes when nothing asks for another one
+func Default(t Type) Type {
+	switch t {
+	case UntypedInt:
+		return I64
+	case UntypedFloat:
+		return F64
+	default:
+		return t
+	}
+}
@@ -0,0 +1,102 @@
+// Package types holds the semantic types of Fracta.
+//
+// Types are canonical: every constructor returns the same value for the same type,
+// so two types are identical exactly when they are equal with ==.
+// Named types are the exception by design, each declaration creates a distinct type.
+package types
+
+// A Fracta type. Implementations are the pointer types of this package.
+type Type interface {
+	String() string
+	typ()
+}
+
+// Kind of a basic type
+type BasicKind int
+
+const (
+	KindInvalid BasicKind = iota // Type of an expression whose analysis failed
+	KindVoid                     // Result of a call to a function without a return type
+
+	KindBool
+	KindI8
+	KindI16
+	KindI32
+	KindI64
+	KindU8
+	KindU16
+	KindU32
+	KindU64
+	KindF32
+	KindF64
+	KindChar // A Unicode code point
+	KindStr  // Pointer to NUL-terminated bytes
+	KindPtr  // Untyped pointer
+
+	// Types of constants before their context gives them a type
+	KindUntypedInt
+	KindUntypedFloat
+	KindUntypedNull
+)
+
+type basicInfo int
+
+const (
+	infoInteger basicInfo = 1 << iota
+	infoUnsigned
+	infoFloat
+	infoUntyped
+)
+
+// A predeclared type
+type Basic struct {
+	kind BasicKind
+	info basicInfo
+	name string
+	size int64
+}
+
+func (*Basic) typ() {}
+
+func (b *Basic) String() string { return b.name }
+
+func (b *Basic) Kind() BasicKind { return b.kind }
+
+var (
+	Invalid = &Basic{kind: KindInvalid, name: "<unknown>"}
+	Void    = &Basic{kind: KindVoid, name: "void"}
+
+	Bool = &Basic{kind: KindBool, name: "bool", size: 1}
+	I8   = &Basic{kind: KindI8, info: infoInteger, name: "i8", size: 1}
+	I16  = &Basic{kind: KindI16, info: infoInteger, name: "i16", size: 2}
+	I32  = &Basic{kind: KindI32, info: infoInteger, name: "i32", size: 4}
+	I64  = &Basic{kind: KindI64, info: infoInteger, name: "i64", size: 8}
+	U8   = &Basic{kind: KindU8, info: infoInteger | infoUnsigned, name: "u8", size: 1}
+	U16  = &Basic{kind: KindU16, info: infoInteger | infoUnsigned, name: "u16", size: 2}
+	U32  = &Basic{kind: KindU32, info: infoInteger | infoUnsigned, name: "u32", size: 4}
+	U64  = &Basic{kind: KindU64, info: infoInteger | infoUnsigned, name: "u64", size: 8}
+	F32  = &Basic{kind: KindF32, info: infoFloat, name: "f32", size: 4}
+	F64  = &Basic{kind: KindF64, info: infoFloat, name: "f64", size: 8}
+	Char = &Basic{kind: KindChar, name: "char", size: 4}
+	Str  = &Basic{kind: KindStr, name: "str", size: PointerSize}
+	Ptr  = &Basic{kind: KindPtr, name: "ptr", size: PointerSize}
+
+	UntypedInt   = &Basic{kind: KindUntypedInt, info: infoInteger | infoUntyped, name: "untyped int"}
+	UntypedFloat = &Basic{kind: KindUntypedFloat, info: infoFloat | infoUntyped, name: "untyped float"}
+	UntypedNull  = &Basic{kind: KindUntypedNull, info: infoUntyped, name: "null"}
+)
+
+// Types that can be written by name in a program
+var universe = map[string]*Basic{}
+
+func init() {
+	for _, b := range []*Basic{Bool, I8, I16, I32, I64, U8, U16, U32, U64, F32, F64, Char, Str, Ptr} {
+		universe[b.name] = b
+	}
+}
+
+// Returns the predeclared type with the given name
+func Lookup(name string) (*Basic, bool) {
+	b, ok := universe[name]
+	return b, ok
+}
@@ -1,39 +1,91 @@
 package main
 
 import (
+	"fmt"
 	"fracta/internal/codegen"
 	"fracta/internal/diag"
 	"fracta/internal/pipeline"
+	"os"
 
 	"github.com/alecthomas/kong"
 	"github.com/davecgh/go-spew/spew"
 )
 
 var CLI struct {
-	File string `arg:"" name:"file" default:"test.fr"`
+	Build   buildCmd   `cmd:"" default:"withargs" help:"Compile a Fracta source file."`
+	Explain explainCmd `cmd:"" help:"Print a detailed description of a diagnostic code."`
 }
 
-func main() {
-	codegen.RegisterAllBackends()
-	spew.Config.Indent = "  "
-	spew.Config.DisablePointerAddresses = true
+type buildCmd struct {
+	File              string `arg:"" name:"file" default:"test.fr"`
+	Output            string `name:"output" short:"o" help:"Write the generated IR to this file instead of standard output."`
+	Color             string `name:"color" enum:"auto,always,never" default:"auto" help:"When to color diagnostics (auto, always, never)."`
+	DiagnosticsFormat string `name:"diagnostics-format" enum:"text,json,sarif" default:"text" help:"Format of the diagnostics written to standard error (text, json, sarif)."`
+	DumpAst           bool   `name:"dump-ast" help:"Dump the analyzed AST after code generation."`
+}
 
-	kong.Parse(&CLI)
-	ast, err := pipeline.SingleFileReadingPipeline("test", CLI.File)
+func (c *buildCmd) Run() error {
+	ast, diags, err := pipeline.SingleFileReadingPipeline("test", c.File)
 
 	if err != nil {
-		switch e := err.(type) {
-		case diag.ErrorList:
-			diag.DiagnoseErrors(e)
-			return
-		default:
-			panic(e)
+		return err
+	}
+
+	mode, _ := diag.ParseColorMode(c.Color)
+	format, _ := diag.ParseFormat(c.DiagnosticsFormat)
+
+	// Machine-readable formats always produce a document, even when empty. Diagnostics go to
+	// standard error, keeping standard output for the IR.
+	if len(diags) != 0 || format != diag.FormatText {
+		if err := diag.WriteDiagnostics(os.Stderr, diags, format, mode); err != nil {
+			return err
 		}
 	}
 
+	if diags.HasErrors() {
+		os.Exit(1)
+	}
+
+	out := os.Stdout
+	if c.Output != "" {
+		f, err := os.Create(c.Output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
 	gen := codegen.GetNewCodeGenerator("llvm")
-	gen.Generate(ast, nil)
+	if err := gen.Generate(ast, out); err != nil {
+		return err
+	}
 
-	spew.Dump(ast)
+	if c.DumpAst {
+		spew.Dump(ast)
+	}
+	return nil
+}
+
+type explainCmd struct {
+	Code string `arg:"" name:"code" help:"Diagnostic code, for example E0101."`
+}
+
+func (c *explainCmd) Run() error {
+	text, err := diag.Explain(c.Code)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(text)
+	return nil
+}
+
+func main() {
+	codegen.RegisterAllBackends()
+	spew.Config.Indent = "  "
+	spew.Config.DisablePointerAddresses = true
 
+	ctx := kong.Parse(&CLI)
+	ctx.FatalIfErrorf(ctx.Run())
 }
@@ -0,0 +1,53 @@
+package diag_test
+
+import (
+	"fracta/internal/diag"
+	"strings"
+	"testing"
+)
+
+func TestCodesAreDocumented(t *testing.T) {
+	for _, c := range diag.AllCodes() {
+		if c.Title() == "" {
+			t.Fatalf("code %s has no title", c)
+		}
+
+		text, err := diag.Explain(string(c))
+		if err != nil {
+			t.Fatalf("code %s cannot be explained: %v", c, err)
+		}
+		if !strings.HasPrefix(text, string(c)+": "+c.Title()) {
+			t.Fatalf("explanation of %s does not start with its title: %q", c, text)
+		}
+	}
+}
+
+func TestExplainIsCaseInsensitive(t *testing.T) {
+	text, err := diag.Explain("e0101")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(text, "E0101: return type mismatch") {
+		t.Fatalf("unexpected explanation: %q", text)
+	}
+
+	if _, err := diag.Explain("E9999"); err == nil {
+		t.Fatalf("expected an error for an unknown code")
+	}
+}
+
+func TestSeverityFromCode(t *testing.T) {
+	e := diag.CreateError(diag.ErrReturnTypeMismatch, "msg", span(1, 1, 1))
+	w := diag.CreateError(diag.Code("W0001"), "msg", span(1, 1, 1))
+
+	if e.Severity != diag.SeverityError || w.Severity != diag.SeverityWarning {
+		t.Fatalf("wrong severities: %v, %v", e.Severity, w.Severity)
+	}
+
+	if diag.ErrorList([]*diag.ErrorContainer{w}).HasErrors() {
+		t.Fatalf("a list of warnings must not have errors")
+	}
+	if !diag.ErrorList([]*diag.ErrorContainer{w, e}).HasErrors() {
+		t.Fatalf("expected the list to have errors")
+	}
+}
@@ -0,0 +1,137 @@
+package diag_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func sampleList() diag.ErrorList {
+	missing := span(2, 14, 0)
+
+	// No check reports warnings yet, the severity of the second one is lowered by hand
+	redefined := diag.CreateError(diag.ErrRedefinition, "symbol redefinition: main", span(4, 5, 10)).
+		WithLabel(span(3, 5, 10), "previous definition here").
+		WithNote("functions can only be defined once")
+	redefined.Severity = diag.SeverityWarning
+
+	return diag.ErrorList{
+		diag.CreateError(diag.ErrExpectedToken, "expected ';'", span(2, 12, 2)).
+			WithFix(missing, ";", "insert ';'"),
+		redefined,
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := diag.WriteJSON(&out, sampleList()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc struct {
+		Diagnostics []struct {
+			Severity string
+			Code     string
+			Message  string
+			Span     struct {
+				File  string
+				Start struct{ Line, Column int }
+			}
+			Labels []struct{ Message string }
+			Notes  []struct{ Severity, Message string }
+			Fixes  []struct {
+				Message string
+				Edits   []struct{ NewText string }
+			}
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if len(doc.Diagnostics) != 2 {
+		t.Fatalf("wrong diagnostic count: %d", len(doc.Diagnostics))
+	}
+
+	first, second := doc.Diagnostics[0], doc.Diagnostics[1]
+	if first.Severity != "error" || first.Code != "E0050" || first.Span.File != "render.fr" || first.Span.Start.Line != 2 || first.Span.Start.Column != 12 {
+		t.Fatalf("wrong first diagnostic: %+v", first)
+	}
+	if len(first.Fixes) != 1 || first.Fixes[0].Edits[0].NewText != ";" {
+		t.Fatalf("wrong fixes: %+v", first.Fixes)
+	}
+	if second.Severity != "warning" || len(second.Labels) != 1 || len(second.Notes) != 1 || second.Notes[0].Severity != "note" {
+		t.Fatalf("wrong second diagnostic: %+v", second)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var out bytes.Buffer
+	if err := diag.WriteSARIF(&out, sampleList()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				RuleIndex int
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn, EndColumn int }
+					}
+				}
+				RelatedLocations []struct{ Message struct{ Text string } }
+				Fixes            []struct {
+					ArtifactChanges []struct {
+						Replacements []struct {
+							InsertedContent struct{ Text string }
+						}
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("wrong log header: %+v", log)
+	}
+
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "fracta" || len(run.Tool.Driver.Rules) != len(diag.AllCodes()) {
+		t.Fatalf("wrong driver: %+v", run.Tool.Driver)
+	}
+
+	for _, res := range run.Results {
+		if run.Tool.Driver.Rules[res.RuleIndex].ID != res.RuleID {
+			t.Fatalf("rule index %d does not match rule %s", res.RuleIndex, res.RuleID)
+		}
+	}
+
+	first := run.Results[0]
+	loc := first.Locations[0].PhysicalLocation
+	if first.Level != "error" || loc.ArtifactLocation.URI != "render.fr" || loc.Region.StartLine != 2 || loc.Region.StartColumn != 12 || loc.Region.EndColumn != 14 {
+		t.Fatalf("wrong first result: %+v", first)
+	}
+	if first.Fixes[0].ArtifactChanges[0].Replacements[0].InsertedContent.Text != ";" {
+		t.Fatalf("wrong fix: %+v", first.Fixes)
+	}
+
+	second := run.Results[1]
+	if second.Level != "warning" || len(second.RelatedLocations) != 1 {
+		t.Fatalf("wrong second result: %+v", second)
+	}
+}
@@ -0,0 +1,86 @@
+package diag_test
+
+import (
+	"bytes"
+	"fracta/internal/diag"
+	tk "fracta/internal/token"
+	"strings"
+	"testing"
+)
+
+func span(line, col, length int) tk.Span {
+	return tk.Span{
+		File:  "render.fr",
+		Start: tk.Position{Line: line, Column: col},
+		End:   tk.Position{Line: line, Column: col + length},
+	}
+}
+
+func TestRenderWithLabels(t *testing.T) {
+	src := "func main() i32 {\n\treturn 2i;\n}\n\nfunc main() i32 {}\n"
+
+	var out bytes.Buffer
+	r := diag.NewRenderer(&out, diag.ColorNever)
+	r.AddSource("render.fr", []byte(src))
+
+	e := diag.CreateError(diag.ErrRedefinition, "symbol redefinition: main", span(5, 6, 4)).
+		WithLabel(span(1, 6, 4), "previous definition here").
+		WithNote("functions can only be defined once")
+	r.Render(e)
+
+	want := "" +
+		"error[E0102]: symbol redefinition: main\n" +
+		" --> render.fr:5:6\n" +
+		"  |\n" +
+		"1 | func main() i32 {\n" +
+		"  |      ---- previous definition here\n" +
+		"...\n" +
+		"5 | func main() i32 {}\n" +
+		"  |      ^^^^\n" +
+		"  |\n" +
+		"  = note: functions can only be defined once\n" +
+		"\n"
+
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRenderExpandsTabs(t *testing.T) {
+	src := "func main() i32 {\n\treturn 2i;\n}\n"
+
+	var out bytes.Buffer
+	r := diag.NewRenderer(&out, diag.ColorNever)
+	r.AddSource("render.fr", []byte(src))
+	r.Render(diag.CreateError(diag.ErrInvalidNumber, "bad literal", span(2, 9, 2)))
+
+	want := "" +
+		"error[E0002]: bad literal\n" +
+		" --> render.fr:2:9\n" +
+		"  |\n" +
+		"2 |     return 2i;\n" +
+		"  |            ^^\n" +
+		"  |\n" +
+		"\n"
+
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRenderColorsUnderlines(t *testing.T) {
+	src := "func main() i32 {\n\treturn 2i;\n}\n\nfunc main() i32 {}\n"
+
+	var out bytes.Buffer
+	r := diag.NewRenderer(&out, diag.ColorAlways)
+	r.AddSource("render.fr", []byte(src))
+	r.Render(diag.CreateError(diag.ErrRedefinition, "symbol redefinition: main", span(5, 6, 4)).
+		WithLabel(span(1, 6, 4), "previous definition here"))
+
+	// The primary underline takes the color of the severity, labels are blue
+	for _, want := range []string{"\x1b[1m\x1b[31m^^^^\x1b[0m", "\x1b[1m\x1b[34m---- previous definition here\x1b[0m"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %q in output:\n%q", want, out.String())
+		}
+	}
+}
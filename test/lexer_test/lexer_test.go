@@ -1,10 +1,16 @@
 package lexer_test
 
 import (
+	"errors"
 	"fmt"
+	"fracta/internal/diag"
 	"fracta/internal/lexer"
 	tk "fracta/internal/token"
+	"go/constant"
+	gotoken "go/token"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -16,14 +22,14 @@ func TestClassifyNumberLiteral(t *testing.T) {
 	}
 
 	ok := []entry{
-		{"0", tk.TokI64, int64(0)},
-		{"1", tk.TokI64, int64(1)},
-		{"123", tk.TokI64, int64(123)},
+		{"0", tk.TokInt, constant.MakeInt64(0)},
+		{"1", tk.TokInt, constant.MakeInt64(1)},
+		{"123", tk.TokInt, constant.MakeInt64(123)},
 
 		// base-2/8/16 integer only
-		{"0b1010", tk.TokI64, int64(0b1010)},
-		{"0o77", tk.TokI64, int64(63)},
-		{"0xFF", tk.TokI64, int64(255)},
+		{"0b1010", tk.TokInt, constant.MakeInt64(0b1010)},
+		{"0o77", tk.TokInt, constant.MakeInt64(63)},
+		{"0xFF", tk.TokInt, constant.MakeInt64(255)},
 
 		// suffix integers
 		{"5b", tk.TokI8, int8(5)},
@@ -37,16 +43,39 @@ func TestClassifyNumberLiteral(t *testing.T) {
 		{"999ul", tk.TokU64, uint64(999)},
 
 		// floats
-		{"1.0", tk.TokF64, float64(1.0)},
-		{"0.5", tk.TokF64, float64(0.5)},
-		{".5", tk.TokF64, float64(0.5)},
+		{"1.0", tk.TokFloat, constant.MakeFloat64(1.0)},
+		{"0.5", tk.TokFloat, constant.MakeFloat64(0.5)},
+		{".5", tk.TokFloat, constant.MakeFloat64(0.5)},
 
 		{"1.5f", tk.TokF32, float32(1.5)},
 		{"2.5d", tk.TokF64, float64(2.5)},
 
 		// scientific notation
-		{"1e3", tk.TokF64, float64(1000)},
-		{"1.5e2", tk.TokF64, float64(150)},
+		{"1e3", tk.TokFloat, constant.MakeFloat64(1000)},
+		{"1.5e2", tk.TokFloat, constant.MakeFloat64(150)},
+
+		// hexadecimal floats
+		{"0x1.8p3", tk.TokFloat, constant.MakeFloat64(12)},
+		{"0x1p-2", tk.TokFloat, constant.MakeFloat64(0.25)},
+		{"0x.8P+1", tk.TokFloat, constant.MakeFloat64(1)},
+		{"0xA.Bp0d", tk.TokF64, float64(10.6875)},
+		{"0x1.fffffep127f", tk.TokF32, float32(math.MaxFloat32)},
+		{"0x1.fffffffffffffp1023", tk.TokFloat, constant.MakeFloat64(math.MaxFloat64)},
+		{"0x1_0.8p0", tk.TokFloat, constant.MakeFloat64(16.5)},
+
+		// digit separators
+		{"1_000_000", tk.TokInt, constant.MakeInt64(1000000)},
+		{"0xFFFF_0000ul", tk.TokU64, uint64(0xFFFF0000)},
+		{"0x_FF", tk.TokInt, constant.MakeInt64(255)},
+		{"0b1010_1010ub", tk.TokU8, uint8(0xAA)},
+		{"0o7_7", tk.TokInt, constant.MakeInt64(63)},
+		{"1_0.2_5e1_0", tk.TokFloat, constant.MakeFloat64(10.25e10)},
+
+		// range limits
+		{"127b", tk.TokI8, int8(127)},
+		{"255ub", tk.TokU8, uint8(255)},
+		{"65535us", tk.TokU16, uint16(65535)},
+		{"18446744073709551615ul", tk.TokU64, uint64(18446744073709551615)},
 	}
 
 	for _, v := range ok {
@@ -57,6 +86,13 @@ func TestClassifyNumberLiteral(t *testing.T) {
 		if kind != v.kind {
 			t.Fatalf("wrong kind for %q: got %v want %v", v.in, kind, v.kind)
 		}
+		// Untyped literals are exact constants, which compare by value
+		if want, ok := v.value.(constant.Value); ok {
+			if !constant.Compare(val.(constant.Value), gotoken.EQL, want) {
+				t.Fatalf("wrong value for %q: got %v want %v", v.in, val, want)
+			}
+			continue
+		}
 		if fmt.Sprintf("%T", val) != fmt.Sprintf("%T", v.value) {
 			t.Fatalf("wrong type for %q: got %T want %T", v.in, val, v.value)
 		}
@@ -67,11 +103,27 @@ func TestClassifyNumberLiteral(t *testing.T) {
 
 	bad := []string{
 		"1.",      // trailing decimal forbidden
-		"0x1.0",   // hex float forbidden
+		"0x1.0",   // hex float needs a p exponent
+		"0x1.8f",  // 'f' is a digit there, still no exponent
+		"0b1.1p1", // binary float forbidden
+		"0o7p1",   // exponent on octal forbidden
+		"1p3",     // p is only an exponent in hexadecimal
 		"0b101e3", // exp on non-decimal forbidden
 		"e10",     // no leading digit
 		".e10",    // invalid
 		"1..2",
+		"1.5b", // integer suffixes on floats
+		"1e3i",
+		"0x1p3l",
+
+		// misplaced separators
+		"1__0",
+		"1_",
+		"0x_",
+		"1_.5",
+		"1._5",
+		"1e_5",
+		"1_i",
 	}
 
 	for _, in := range bad {
@@ -81,3 +133,269 @@ func TestClassifyNumberLiteral(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenSpans(t *testing.T) {
+	src := "func main() i32 {\n    return 2i;\n}"
+	lex := lexer.NewLexerFromReader(strings.NewReader(src), "spans.fr")
+
+	toks, err := lex.GetAllTokens()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type entry struct {
+		kind       tk.TokenType
+		start, end tk.Position
+	}
+
+	want := []entry{
+		{tk.TokKwFunc, tk.Position{Offset: 0, Line: 1, Column: 1}, tk.Position{Offset: 4, Line: 1, Column: 5}},
+		{tk.TokIdentifier, tk.Position{Offset: 5, Line: 1, Column: 6}, tk.Position{Offset: 9, Line: 1, Column: 10}},
+		{tk.TokOpenParen, tk.Position{Offset: 9, Line: 1, Column: 10}, tk.Position{Offset: 10, Line: 1, Column: 11}},
+		{tk.TokCloseParen, tk.Position{Offset: 10, Line: 1, Column: 11}, tk.Position{Offset: 11, Line: 1, Column: 12}},
+		{tk.TokIdentifier, tk.Position{Offset: 12, Line: 1, Column: 13}, tk.Position{Offset: 15, Line: 1, Column: 16}},
+		{tk.TokOpenBracket, tk.Position{Offset: 16, Line: 1, Column: 17}, tk.Position{Offset: 17, Line: 1, Column: 18}},
+		{tk.TokKwReturn, tk.Position{Offset: 22, Line: 2, Column: 5}, tk.Position{Offset: 28, Line: 2, Column: 11}},
+		{tk.TokI32, tk.Position{Offset: 29, Line: 2, Column: 12}, tk.Position{Offset: 31, Line: 2, Column: 14}},
+		{tk.TokSemicolon, tk.Position{Offset: 31, Line: 2, Column: 14}, tk.Position{Offset: 32, Line: 2, Column: 15}},
+		{tk.TokCloseBracket, tk.Position{Offset: 33, Line: 3, Column: 1}, tk.Position{Offset: 34, Line: 3, Column: 2}},
+		{tk.TokEndOfFile, tk.Position{Offset: 34, Line: 3, Column: 2}, tk.Position{Offset: 34, Line: 3, Column: 2}},
+	}
+
+	if len(toks) != len(want) {
+		t.Fatalf("wrong token count: got %d want %d", len(toks), len(want))
+	}
+
+	for i, w := range want {
+		got := toks[i]
+		if got.Kind != w.kind {
+			t.Fatalf("token %d: wrong kind: got %v want %v", i, got.Kind, w.kind)
+		}
+		if got.Span.File != "spans.fr" {
+			t.Fatalf("token %d: wrong file: got %q", i, got.Span.File)
+		}
+		if got.Span.Start != w.start || got.Span.End != w.end {
+			t.Fatalf("token %d (%v): wrong span: got %v-%v want %v-%v", i, got, got.Span.Start, got.Span.End, w.start, w.end)
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	src := "return \"open;\nx = 1.x + 0b12 @ 12q;\n'a\ny $$ 'bc';"
+	lex := lexer.NewLexerFromReader(strings.NewReader(src), "recovery.fr")
+
+	toks, err := lex.GetAllTokens()
+
+	var list diag.ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected an error list, got %v", err)
+	}
+
+	wantCodes := []diag.Code{
+		diag.ErrUnterminatedString,
+		diag.ErrInvalidNumber,
+		diag.ErrInvalidNumber,
+		diag.ErrUnexpectedCharacter,
+		diag.ErrInvalidNumber,
+		diag.ErrUnterminatedChar,
+		diag.ErrUnexpectedCharacter,
+		diag.ErrInvalidChar,
+	}
+
+	if len(list) != len(wantCodes) {
+		t.Fatalf("wrong error count: got %d want %d: %v", len(list), len(wantCodes), list)
+	}
+	for i, c := range wantCodes {
+		if list[i].Code != c {
+			t.Fatalf("error %d: got %s want %s (%s)", i, list[i].Code, c, list[i].Message)
+		}
+	}
+
+	wantKinds := []tk.TokenType{
+		tk.TokKwReturn, tk.TokError,
+		tk.TokIdentifier, tk.TokOpAssign, tk.TokError, tk.TokOpPlus, tk.TokError, tk.TokError, tk.TokError, tk.TokSemicolon,
+		tk.TokError,
+		tk.TokIdentifier, tk.TokError, tk.TokError, tk.TokSemicolon,
+		tk.TokEndOfFile,
+	}
+
+	if len(toks) != len(wantKinds) {
+		t.Fatalf("wrong token count: got %d want %d: %v", len(toks), len(wantKinds), toks)
+	}
+	for i, k := range wantKinds {
+		if toks[i].Kind != k {
+			t.Fatalf("token %d: got %v want %v", i, toks[i].Kind, k)
+		}
+	}
+}
+
+func TestTriviaRoundTrip(t *testing.T) {
+	src := "// header\r\nfunc main() i32 { /* inline */ return 1i; } // done\n\n\t/* tail\n*/ \n"
+	lex := lexer.NewLexerFromReader(strings.NewReader(src), "trivia.fr")
+	lex.SetTriviaMode(true)
+
+	toks, err := lex.GetAllTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	for _, v := range toks {
+		sb.WriteString(v.FullText())
+	}
+	if sb.String() != src {
+		t.Fatalf("round trip mismatch:\ngot  %q\nwant %q", sb.String(), src)
+	}
+
+	first := toks[0]
+	if first.Kind != tk.TokKwFunc || len(first.LeadingTrivia) != 3 || first.LeadingTrivia[0].Kind != tk.TriviaLineComment {
+		t.Fatalf("unexpected leading trivia on %v: %+v", first, first.LeadingTrivia)
+	}
+
+	// The comment after the closing bracket stays on its line, the rest goes to the end of file token
+	closing := toks[len(toks)-2]
+	if closing.Kind != tk.TokCloseBracket || len(closing.TrailingTrivia) != 2 || closing.TrailingTrivia[1].Text != "// done" {
+		t.Fatalf("unexpected trailing trivia on %v: %+v", closing, closing.TrailingTrivia)
+	}
+
+	eof := toks[len(toks)-1]
+	if len(eof.LeadingTrivia) != 6 || eof.LeadingTrivia[3].Span.Start.Line != 4 {
+		t.Fatalf("unexpected leading trivia on end of file: %+v", eof.LeadingTrivia)
+	}
+
+	// The default mode keeps none of it
+	plain, _ := lexer.NewLexerFromReader(strings.NewReader(src), "trivia.fr").GetAllTokens()
+	if len(plain) != len(toks) || plain[0].LeadingTrivia != nil || plain[0].Lexeme != "" {
+		t.Fatalf("trivia leaked into the default mode: %+v", plain[0])
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	type entry struct {
+		in   string
+		want string
+	}
+
+	entries := []entry{
+		{`"a\tb\\c\"d"`, "a\tb\\c\"d"},
+		{`"\x41\x7f\101\0"`, "A\x7fA\x00"},
+		{`"\xff"`, "\xff"},
+		{`"\u{48}\u{e9}\u{1F600}"`, "H\u00e9\U0001F600"},
+		{"`C:\\path\\{}\r\n\"quoted\"`", "C:\\path\\{}\n\"quoted\""},
+		{"\"\"\"\n    {\"a\": 1}\n\n      nested\\t\n    \"\"\"", "{\"a\": 1}\n\n  nested\t"},
+		{"\"\"\"  \r\n\tline\r\n\t\"\"\"", "line"},
+	}
+
+	for _, v := range entries {
+		toks, err := lexer.NewLexerFromReader(strings.NewReader(v.in), "str.fr").GetAllTokens()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", v.in, err)
+		}
+		if len(toks) != 2 || toks[0].Kind != tk.TokString {
+			t.Fatalf("%s: expected a single string token, got %v", v.in, toks)
+		}
+		if toks[0].Value != v.want {
+			t.Fatalf("%s: got %q want %q", v.in, toks[0].Value, v.want)
+		}
+	}
+}
+
+func TestEscapeErrors(t *testing.T) {
+	type entry struct {
+		in     string
+		code   diag.Code
+		column int // Column of the first error
+		msg    string
+	}
+
+	entries := []entry{
+		{`"ab\qc"`, diag.ErrInvalidEscape, 4, `unknown escape sequence "\\q"`},
+		{`"\u{D800}"`, diag.ErrInvalidCodePoint, 2, "U+D800 is a surrogate, which is not a valid code point"},
+		{`"xy\u{110000}"`, diag.ErrInvalidCodePoint, 4, "U+110000 is out of range, the largest code point is U+10FFFF"},
+		{`"\x4"`, diag.ErrInvalidEscape, 2, `\x escape needs exactly two hex digits, like \x7F`},
+		{`"\400"`, diag.ErrInvalidEscape, 2, `octal escape \400 is larger than \377`},
+		{`"\u0041"`, diag.ErrInvalidEscape, 2, `\u escape must be written as \u{...} with 1 to 6 hex digits`},
+		{`'\xe9'`, diag.ErrInvalidEscape, 2, `byte escape \xe9 is not ASCII, write the code point as \u{E9} in character literals`},
+		{"\"\"\" x\n  a\n \"\"\"", diag.ErrInvalidMultilineString, 5, "multi-line string content must start on the line after the opening '\"\"\"'"},
+		{"\"\"\"\n  a\n b\n  \"\"\"", diag.ErrInvalidMultilineString, 1, "line is indented less than the closing '\"\"\"'"},
+		{"`never closed\n", diag.ErrUnterminatedString, 1, "unterminated raw string literal"},
+	}
+
+	for _, v := range entries {
+		toks, err := lexer.NewLexerFromReader(strings.NewReader(v.in), "esc.fr").GetAllTokens()
+
+		var list diag.ErrorList
+		if !errors.As(err, &list) {
+			t.Fatalf("%s: expected an error list, got %v", v.in, err)
+		}
+		if list[0].Code != v.code || list[0].Message != v.msg || list[0].Span.Start.Column != v.column {
+			t.Fatalf("%s: got %s at column %d: %q", v.in, list[0].Code, list[0].Span.Start.Column, list[0].Message)
+		}
+		if toks[0].Kind != tk.TokError {
+			t.Fatalf("%s: expected an error token, got %v", v.in, toks[0])
+		}
+	}
+
+	// Every bad escape of a literal is reported, with its exact span
+	_, err := lexer.NewLexerFromReader(strings.NewReader(`"\q ok \u{DFFF}"`), "esc.fr").GetAllTokens()
+	list := err.(diag.ErrorList)
+	if len(list) != 2 || list[1].Span.Start.Column != 8 || list[1].Span.End.Column != 16 {
+		t.Fatalf("unexpected errors: %v", list)
+	}
+}
+
+func TestLiteralOverflow(t *testing.T) {
+	type entry struct {
+		in  string
+		msg string
+	}
+
+	entries := []entry{
+		{"300b", "literal 300b overflows i8"},
+		{"128b", "literal 128b overflows i8"},
+		{"70000us", "literal 70000us overflows u16"},
+		{"0x1_0000_0000ui", "literal 0x1_0000_0000ui overflows u32"},
+		{"9223372036854775808l", "literal 9223372036854775808l overflows i64"},
+		{"1e39f", "literal 1e39f overflows f32"},
+		{"1e309d", "literal 1e309d overflows f64"},
+	}
+
+	for _, v := range entries {
+		_, err := lexer.NewLexerFromReader(strings.NewReader(v.in), "num.fr").GetAllTokens()
+
+		var list diag.ErrorList
+		if !errors.As(err, &list) || len(list) != 1 {
+			t.Fatalf("%s: expected a single error, got %v", v.in, err)
+		}
+		if list[0].Code != diag.ErrLiteralOverflow || list[0].Message != v.msg {
+			t.Fatalf("%s: got %s %q", v.in, list[0].Code, list[0].Message)
+		}
+	}
+
+	_, err := lexer.NewLexerFromReader(strings.NewReader("300b"), "num.fr").GetAllTokens()
+	if note := err.(diag.ErrorList)[0].Notes[0].Message; note != "the range of i8 is -128 to 127" {
+		t.Fatalf("unexpected note %q", note)
+	}
+}
+
+func TestFloatLiteralTokens(t *testing.T) {
+	toks, err := lexer.NewLexerFromReader(strings.NewReader("0x1.8p-3f + inf - nanf * 0x1.8p+3 + infinity"), "f.fr").GetAllTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Special float values are predeclared constants, lexed as identifiers
+	wantKinds := []tk.TokenType{tk.TokF32, tk.TokOpPlus, tk.TokIdentifier, tk.TokOpMinus, tk.TokIdentifier, tk.TokOpStar, tk.TokFloat, tk.TokOpPlus, tk.TokIdentifier, tk.TokEndOfFile}
+	if len(toks) != len(wantKinds) {
+		t.Fatalf("wrong token count: %v", toks)
+	}
+	for i, k := range wantKinds {
+		if toks[i].Kind != k {
+			t.Fatalf("token %d: got %v want %v", i, toks[i].Kind, k)
+		}
+	}
+
+	if toks[0].Value != float32(0.1875) || !constant.Compare(toks[6].Value.(constant.Value), gotoken.EQL, constant.MakeInt64(12)) {
+		t.Fatalf("wrong hex float values: %v %v", toks[0].Value, toks[6].Value)
+	}
+}
@@ -0,0 +1,102 @@
+package parser_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/lexer"
+	"fracta/internal/parser"
+	"strings"
+	"testing"
+)
+
+func TestDocComments(t *testing.T) {
+	src := `/// Adds two numbers.
+///
+/// Wraps on overflow.
+func add(a i32, b i32) i32 { return a + b; }
+
+/**
+ * Entry point.
+ *   Indented.
+ */
+func main() i32 { return add(1i, 2i); }
+
+/// Detached by the blank line
+
+// Regular comment
+func a() {}
+
+/// Detached by the regular comment
+// Regular comment
+func b() {}
+
+//// Not a doc comment
+/***/
+func c() {}
+`
+
+	toks, err := lexer.NewLexerFromReader(strings.NewReader(src), "doc.fr").GetAllTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsn, err := parser.NewParser(toks, "doc.fr").Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"Adds two numbers.\n\nWraps on overflow.",
+		"Entry point.\n  Indented.",
+		"",
+		"",
+		"",
+	}
+
+	if len(fsn.Statements) != len(want) {
+		t.Fatalf("got %d statements, want %d", len(fsn.Statements), len(want))
+	}
+	for i, v := range fsn.Statements {
+		fd := v.(*ast.FunctionDeclaration)
+		if fd.Doc != want[i] {
+			t.Errorf("%s: got doc %q, want %q", fd.Name.Identifier, fd.Doc, want[i])
+		}
+	}
+}
+
+func TestVariableDocComments(t *testing.T) {
+	src := `func main() {
+    /// Number of retries.
+    var retries = 3i;
+
+    /// Upper bound,
+    /// inclusive.
+    let limit = 10i;
+
+    // Regular comment
+    let plain = 1i;
+}
+`
+
+	toks, err := lexer.NewLexerFromReader(strings.NewReader(src), "doc.fr").GetAllTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsn, err := parser.NewParser(toks, "doc.fr").Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Number of retries.", "Upper bound,\ninclusive.", ""}
+
+	body := fsn.Statements[0].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+	if len(body) != len(want) {
+		t.Fatalf("got %d statements, want %d", len(body), len(want))
+	}
+	for i, v := range body {
+		vd := v.(*ast.VariableDeclaration)
+		if vd.Doc != want[i] {
+			t.Errorf("%s: got doc %q, want %q", vd.Name.Identifier, vd.Doc, want[i])
+		}
+	}
+}
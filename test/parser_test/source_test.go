@@ -0,0 +1,58 @@
+package parser_test
+
+import (
+	"fracta/internal/lexer"
+	"fracta/internal/parser"
+	tk "fracta/internal/token"
+	"strings"
+	"testing"
+)
+
+const streamSrc = "func add(a i32, b i32) i32 { return a + b; }\nfunc main() i32 { return add(1i, 2i); }\n"
+
+func TestTokensMatchesGetAllTokens(t *testing.T) {
+	all, err := lexer.NewLexerFromReader(strings.NewReader(streamSrc), "s.fr").GetAllTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i := 0
+	for v := range lexer.NewLexerFromReader(strings.NewReader(streamSrc), "s.fr").Tokens() {
+		if i >= len(all) {
+			t.Fatalf("iterator yielded more than %d tokens", len(all))
+		}
+		if v.Kind != all[i].Kind || v.Span != all[i].Span {
+			t.Fatalf("token %d: got %v want %v", i, v, all[i])
+		}
+		i++
+	}
+
+	if i != len(all) || all[i-1].Kind != tk.TokEndOfFile {
+		t.Fatalf("iterator yielded %d tokens, want %d ending in EOF", i, len(all))
+	}
+}
+
+func TestParseFromSources(t *testing.T) {
+	sources := map[string]func() parser.TokenSource{
+		"lexer": func() parser.TokenSource {
+			return lexer.NewLexerFromReader(strings.NewReader(streamSrc), "s.fr")
+		},
+		"seq": func() parser.TokenSource {
+			return parser.SeqSource(lexer.NewLexerFromReader(strings.NewReader(streamSrc), "s.fr").Tokens())
+		},
+		"slice": func() parser.TokenSource {
+			toks, _ := lexer.NewLexerFromReader(strings.NewReader(streamSrc), "s.fr").GetAllTokens()
+			return parser.SliceSource(toks)
+		},
+	}
+
+	for name, src := range sources {
+		fsn, err := parser.NewParserFromSource(src(), "s.fr").Parse()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(fsn.Statements) != 2 {
+			t.Fatalf("%s: got %d statements, want 2", name, len(fsn.Statements))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func TestArrays(t *testing.T) {
+	fsn, list := analyze(t, `
+func main(s []u8) i64 {
+    var a = [1i, 2i, 3i];
+    var b [2]f64 = [1, 2.5];
+    var c = [[1, 2], [3.5, 4]];
+    var grid [2][3]u8;
+    let d = a[1:];
+    let e = s[:len(s) - 1l];
+    let f = &grid[1][2];
+    var n = len(a);
+    grid[1][2] = s[0];
+    return n + len(d);
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	body := fsn.Statements[0].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+	want := []string{"[3]i32", "[2]f64", "[2][2]f64", "[2][3]u8", "[]i32", "[]u8", "*u8", "i64"}
+	for i, w := range want {
+		vd := body[i].(*ast.VariableDeclaration)
+		if vd.VarType.String() != w {
+			t.Fatalf("%s: got type %s want %s", vd.Name.Identifier, vd.VarType, w)
+		}
+	}
+
+	// The length of an array is known when compiling
+	if body[7].(*ast.VariableDeclaration).Value.ExprNode().Const == nil {
+		t.Fatalf("len of an array is not a constant")
+	}
+}
+
+func TestLenKeepsSideEffects(t *testing.T) {
+	fsn, list := analyze(t, `
+func side() [4]i32 { var a [4]i32; return a; }
+func f(p *[2]u8) i64 {
+    var a [3][2]u8;
+    var i = 1l;
+    let x = len(side());
+    let y = len(a[i]);
+    let z = len(*p);
+    return x + y + z;
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	body := fsn.Statements[1].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+	for i, folded := range []bool{false, true, true} {
+		vd := body[2+i].(*ast.VariableDeclaration)
+		if got := vd.Value.ExprNode().Const != nil; got != folded {
+			t.Fatalf("%s: folded is %v, want %v", vd.Name.Identifier, got, folded)
+		}
+	}
+}
+
+func TestArrayErrors(t *testing.T) {
+	expectCodes(t, `func f() { var a [3]i32; a[3] = 1i; }`, diag.ErrIndexOutOfBounds)
+	expectCodes(t, `func f() { var a [3]i32; a[-1] = 1i; }`, diag.ErrIndexOutOfBounds)
+	expectCodes(t, `func f() { var a [3]i32; let s = a[2:1]; }`, diag.ErrIndexOutOfBounds)
+	expectCodes(t, `func f() { var a [3]i32; let s = a[:4]; }`, diag.ErrIndexOutOfBounds)
+	expectCodes(t, `func f() { var a [0]i32; a[0] = 1i; }`, diag.ErrIndexOutOfBounds)
+	expectCodes(t, `func f(s []i32) { let n = s[-1]; }`, diag.ErrIndexOutOfBounds)
+	expectCodes(t, `func f() i32 { var a [3]i32; return a[5i]; }`, diag.ErrIndexOutOfBounds)
+	expectCodes(t, `func f() { var a [3]i32; let s = a[2l:1l]; }`, diag.ErrIndexOutOfBounds)
+	expectCodes(t, `func f() { var a [3]i32; let s = a[1ub:4ub]; }`, diag.ErrIndexOutOfBounds)
+	expectCodes(t, `func f() i32 { var a [3]i32; return a[2i]; }`)
+	expectCodes(t, `func f() i32 { var a [3]i32; return a[1 << 70]; }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f() { var a [2][2]i32; a[0, 1] = 1i; }`, diag.ErrIndexCount)
+	expectCodes(t, `func f() { var a [2]i32; a[] = 1i; }`, diag.ErrIndexCount)
+	expectCodes(t, `func f(k i32) { let n = k[0]; }`, diag.ErrNotIndexable)
+	expectCodes(t, `func f(k i32) { let n = k[1:]; }`, diag.ErrNotIndexable)
+	expectCodes(t, `func f() { var a [2]i32; let n = a[true]; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func f() { let a = [1i, 2i]; let s = a[:]; }`, diag.ErrNotAddressable)
+	expectCodes(t, `func f() { let a = [1i, 2i]; a[0] = 3i; }`, diag.ErrAssignToImmutable)
+	expectCodes(t, `func f(k i32) { var a [k]i32; }`, diag.ErrInvalidArrayLength)
+	expectCodes(t, `func f() { var a [-1]i32; }`, diag.ErrInvalidArrayLength)
+	expectCodes(t, `func f() { let a = []; }`, diag.ErrCannotInferType)
+	expectCodes(t, `func f() { let a = [null]; }`, diag.ErrCannotInferType)
+	expectCodes(t, `func f() { let a = [1i, 2l]; }`, diag.ErrTypeMismatch)
+	expectCodes(t, `func f() { var a [2]bool = [1, 2]; }`, diag.ErrTypeMismatch)
+	expectCodes(t, `func f() { var a [3]i32 = [1, 2]; }`, diag.ErrTypeMismatch)
+	expectCodes(t, `func f() { let l = len; }`, diag.ErrBuiltinValue)
+	expectCodes(t, `func f(k i32) { let n = len(k); }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func f() { let n = len(); }`, diag.ErrArgumentCount)
+
+	// Builtins live in the universe scope, declarations can hide them
+	expectCodes(t, `func len() { }`)
+	expectCodes(t, `func len() { } func len() { }`, diag.ErrRedefinition)
+	expectCodes(t, `func f(len i64) i64 { return len; }`)
+	expectCodes(t, `func f() { var len = 1i; len += 1i; }`)
+	expectCodes(t, `func f() { var a [2]i32; { let len = 1i; } let n = len(a); }`)
+	expectCodes(t, `func f() { var a [2]i32; let len = 1i; let n = len(a); }`, diag.ErrNotCallable)
+}
@@ -0,0 +1,49 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func TestAssignments(t *testing.T) {
+	fsn, list := analyze(t, `
+func main() i32 {
+    var a = 1i;
+    var b u8 = 2ub;
+    var p ptr;
+    a = a + 1i;
+    a += 2i;
+    a *= a -= 1i;
+    b <<= 1ub;
+    b ^= 255ub;
+    p = null;
+    return a = 3i;
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	body := fsn.Statements[0].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+
+	// Assignment is right associative and evaluates to the type of its target
+	as := body[5].(*ast.ExpressionStatement).Expression.(*ast.Assignment)
+	if _, ok := as.Value.(*ast.Assignment); !ok {
+		t.Fatalf("a *= a -= 1i: right side is %T, want an assignment", as.Value)
+	}
+	if as.Type.String() != "i32" {
+		t.Fatalf("assignment has type %s, want i32", as.Type)
+	}
+}
+
+func TestAssignmentErrors(t *testing.T) {
+	expectCodes(t, `func f() { let a = 1i; a = 2i; }`, diag.ErrAssignToImmutable)
+	expectCodes(t, `func f() { let a = 1i; a += 2i; }`, diag.ErrAssignToImmutable)
+	expectCodes(t, `func f() { f = f; }`, diag.ErrAssignToFunction)
+	expectCodes(t, `func f() { var a = 1i; a + 1i = 2i; }`, diag.ErrNotAssignable)
+	expectCodes(t, `func f() { 1i = 2i; }`, diag.ErrNotAssignable)
+	expectCodes(t, `func f() { var a = 1i; a = 2l; }`, diag.ErrTypeMismatch)
+	expectCodes(t, `func f() { var a = 1i; a += 2l; }`, diag.ErrMismatchedOperands)
+	expectCodes(t, `func f() { var a = 1.0; a |= 2.0; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func f() { b = 1i; }`, diag.ErrUndefinedSymbol)
+}
@@ -0,0 +1,53 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func TestCalls(t *testing.T) {
+	fsn, list := analyze(t, `
+func add(a i32, b i32) i32 { return a + b; }
+func log(msg str);
+func main() i32 {
+    log("hi");
+    let n = add(1i, add(2i, 3i));
+    return n;
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	// Parameters resolve to their declaration
+	add := fsn.Statements[0].(*ast.FunctionDeclaration)
+	ret := add.Body.(*ast.BlockStatement).Body[0].(*ast.ReturnStatement).Value.(*ast.Binary)
+	if ret.Left.(*ast.Identifier).Decl != &add.Args[0] {
+		t.Fatalf("a resolved to %v, want the first parameter", ret.Left.(*ast.Identifier).Decl)
+	}
+
+	body := fsn.Statements[2].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+	if typ := body[0].(*ast.ExpressionStatement).Expression.ExprNode().Type; typ.String() != "void" {
+		t.Fatalf("call to log has type %s, want void", typ)
+	}
+	if typ := body[1].(*ast.VariableDeclaration).VarType; typ.String() != "i32" {
+		t.Fatalf("n has type %s, want i32", typ)
+	}
+}
+
+func TestCallErrors(t *testing.T) {
+	expectCodes(t, `func f(a Meters) { }`, diag.ErrUndefinedSymbol)
+	expectCodes(t, `func f(a i32, a i64) { }`, diag.ErrRedefinition)
+	expectCodes(t, `func f(a i32) { var a = 1i; }`, diag.ErrRedefinition)
+	expectCodes(t, `func f(a i32) { a = 2i; }`, diag.ErrAssignToImmutable)
+	expectCodes(t, `func f(a i32) { a(); }`, diag.ErrNotCallable)
+	expectCodes(t, `func f(a i32) { f(); }`, diag.ErrArgumentCount)
+	expectCodes(t, `func f(a i32) { f(1i, 2i); }`, diag.ErrArgumentCount)
+	expectCodes(t, `func f(a i32) { f(1l); }`, diag.ErrTypeMismatch)
+	expectCodes(t, `func f() { g(); }`, diag.ErrUndefinedSymbol)
+	expectCodes(t, `func f() { let x = f(); }`, diag.ErrCannotInferType)
+	expectCodes(t, `func f() i32 { return g(); } func g() { }`, diag.ErrReturnTypeMismatch)
+
+	// A call keeps its type when the arguments are wrong
+	expectCodes(t, `func f(a i32) i32 { return f(1l) + 1l; }`, diag.ErrTypeMismatch, diag.ErrMismatchedOperands)
+}
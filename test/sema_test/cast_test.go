@@ -0,0 +1,58 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func TestCasts(t *testing.T) {
+	fsn, list := analyze(t, `
+func main() i32 {
+    var x = 300i;
+    let a = x as u8;
+    let b = -x as f64 * 2.0;
+    let c = 3.9 as i32;
+    let d = 255 as u8;
+    let e = 300 as u8;
+    let f = x as i64 as ptr;
+    return b as i32 + a as i32;
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	body := fsn.Statements[0].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+	want := []string{"u8", "f64", "i32", "u8", "u8", "ptr"}
+	for i, w := range want {
+		vd := body[i+1].(*ast.VariableDeclaration)
+		if vd.VarType.String() != w {
+			t.Fatalf("%s: got type %s want %s", vd.Name.Identifier, vd.VarType, w)
+		}
+	}
+
+	// 'as' binds tighter than '*' but looser than prefix operators
+	b := body[2].(*ast.VariableDeclaration).Value.(*ast.Binary)
+	if _, ok := b.Left.(*ast.Cast).Value.(*ast.Unary); !ok {
+		t.Fatalf("-x as f64 * 2.0 parsed as %T", b.Left)
+	}
+
+	// Constants convert exactly when they fit, and from their default type otherwise
+	if c := body[4].(*ast.VariableDeclaration).Value.(*ast.Cast).Value.ExprNode().Type.String(); c != "u8" {
+		t.Fatalf("255 in 255 as u8 has type %s, want u8", c)
+	}
+	if c := body[5].(*ast.VariableDeclaration).Value.(*ast.Cast).Value.ExprNode().Type.String(); c != "i64" {
+		t.Fatalf("300 in 300 as u8 has type %s, want i64", c)
+	}
+}
+
+func TestCastErrors(t *testing.T) {
+	expectCodes(t, `func f() { let a = f as f32; }`, diag.ErrInvalidCast)
+	expectCodes(t, `func f() { let a = 1 as bool; }`, diag.ErrInvalidCast)
+	expectCodes(t, `func f() { var p ptr; let a = p as f64; }`, diag.ErrInvalidCast)
+	expectCodes(t, `func f(p *i32) { let a = p as *f64; }`, diag.ErrInvalidCast)
+	expectCodes(t, `func f(p *i32) { let a = p as ptr as *f64; }`)
+	expectCodes(t, `func f() { let a = "s" as i64; }`, diag.ErrInvalidCast)
+	expectCodes(t, `func f() { let a = 1.5 as char; }`, diag.ErrInvalidCast)
+	expectCodes(t, `func f() { let a i32 = 1i as i64; }`, diag.ErrTypeMismatch)
+}
@@ -0,0 +1,104 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func TestIfStatements(t *testing.T) {
+	fsn, list := analyze(t, `
+func sign(x i32) i32 {
+    var a = 1i;
+    if a > 0i {
+        return 1i;
+    } else if a == 0i {
+        let b = 0i;
+        return b;
+    } else {
+        let b = -1i;
+        return b;
+    }
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	body := fsn.Statements[0].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+	st := body[1].(*ast.IfStatement)
+	elseIf, ok := st.Else.(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("else branch is %T, want an if statement", st.Else)
+	}
+	if _, ok := elseIf.Else.(*ast.BlockStatement); !ok {
+		t.Fatalf("final else branch is %T, want a block", elseIf.Else)
+	}
+}
+
+func TestIfStatementErrors(t *testing.T) {
+	expectCodes(t, `func f() { if 1i { } }`, diag.ErrNonBoolCondition)
+	expectCodes(t, `func f() { var p ptr; if true { } else if p { } }`, diag.ErrNonBoolCondition)
+	expectCodes(t, `func f() { if true { let a = 1i; } let b = a; }`, diag.ErrUndefinedSymbol)
+	expectCodes(t, `func f() { if true { let a = 1i; } else { let b = a; } }`, diag.ErrUndefinedSymbol)
+}
+
+func TestMissingReturn(t *testing.T) {
+	expectCodes(t, `func g() i32 { }`, diag.ErrMissingReturn)
+	expectCodes(t, `func f(x i32) i32 { if x > 0i { return 1i; } }`, diag.ErrMissingReturn)
+	expectCodes(t, `func f(x i32) i32 { loop { if x > 0i { break; } } }`, diag.ErrMissingReturn)
+	expectCodes(t, `func f(x i32) i32 { while x > 0i { return 1i; } }`, diag.ErrMissingReturn)
+
+	expectCodes(t, `func f(x i32) i32 { if x > 0i { return 1i; } else { return 0i; } }`)
+	expectCodes(t, `func f(x i32) i32 { { return x; } }`)
+	expectCodes(t, `func f() i32 { loop { } }`)
+	expectCodes(t, `func f() { }`)
+	expectCodes(t, `func f() i32;`)
+}
+
+func TestLoops(t *testing.T) {
+	fsn, list := analyze(t, `
+func f() i32 {
+    var n = 0i;
+    for var i = 0i; i < 10i; i += 1i {
+        n += i;
+    }
+    while n > 0i { n -= 1i; continue; }
+    outer: for ;; {
+        loop {
+            if n > 3i { break outer; }
+            continue outer;
+        }
+    }
+    return n;
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	body := fsn.Statements[0].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+	outer := body[3].(*ast.ForStatement)
+	if outer.Label == nil || outer.Label.Identifier != "outer" {
+		t.Fatalf("missing label on the outer loop")
+	}
+
+	inner := outer.Body.Body[0].(*ast.LoopStatement)
+	brk := inner.Body.Body[0].(*ast.IfStatement).Then.Body[0].(*ast.BreakStatement)
+	if brk.Target != outer {
+		t.Fatalf("break outer targets %T, want the outer loop", brk.Target)
+	}
+	cont := inner.Body.Body[1].(*ast.ContinueStatement)
+	if cont.Target != outer {
+		t.Fatalf("continue outer targets %T, want the outer loop", cont.Target)
+	}
+}
+
+func TestLoopErrors(t *testing.T) {
+	expectCodes(t, `func f() { break; }`, diag.ErrJumpOutsideLoop)
+	expectCodes(t, `func f() { if true { continue; } }`, diag.ErrJumpOutsideLoop)
+	expectCodes(t, `func f() { loop { break outer; } }`, diag.ErrUndefinedLabel)
+	expectCodes(t, `func f() { a: loop { break; } loop { continue a; } }`, diag.ErrUndefinedLabel)
+	expectCodes(t, `func f() { a: loop { a: loop { break a; } } }`, diag.ErrRedefinition)
+	expectCodes(t, `func f() { while 1i { } }`, diag.ErrNonBoolCondition)
+	expectCodes(t, `func f() { for var i = 0i; i; i += 1i { } }`, diag.ErrNonBoolCondition)
+	expectCodes(t, `func f() { for var i = 0i; i < 3i; i += 1i { } let j = i; }`, diag.ErrUndefinedSymbol)
+}
@@ -0,0 +1,48 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"fracta/internal/lexer"
+	"fracta/internal/parser"
+	"fracta/internal/sema"
+	"strings"
+	"testing"
+)
+
+// Lexes, parses and analyzes src, which must be free of syntax errors
+func analyze(t *testing.T, src string) (*ast.FileSourceNode, diag.ErrorList) {
+	t.Helper()
+
+	lex := lexer.NewLexerFromReader(strings.NewReader(src), "test.fr")
+	fsn, err := parser.NewParserFromSource(lex, "test.fr").Parse()
+	if err != nil {
+		t.Fatalf("syntax errors: %v", err)
+	}
+	if err := lex.Errors(); err != nil {
+		t.Fatalf("lexing errors: %v", err)
+	}
+
+	sm, err := sema.NewAnalyzer("test", fsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = sm.Analyze()
+
+	return fsn, sm.Diagnostics()
+}
+
+// Checks that analyzing src reports exactly the given codes, in order
+func expectCodes(t *testing.T, src string, codes ...diag.Code) {
+	t.Helper()
+
+	_, list := analyze(t, src)
+	if len(list) != len(codes) {
+		t.Fatalf("got %d diagnostics, want %d:\n%v", len(list), len(codes), list)
+	}
+	for i, c := range codes {
+		if list[i].Code != c {
+			t.Fatalf("diagnostic %d: got %s want %s: %s", i, list[i].Code, c, list[i].Message)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func TestLiteralTypes(t *testing.T) {
+	src := `
+func a() bool { return true; }
+func b() bool { return false; }
+func c() char { return 'x'; }
+func d() str { return "text"; }
+func e() ptr { return null; }
+`
+	fsn, list := analyze(t, src)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	want := []string{"bool", "bool", "char", "str", "ptr"}
+	for i, st := range fsn.Statements {
+		body := st.(*ast.FunctionDeclaration).Body.(*ast.BlockStatement)
+		ret := body.Body[0].(*ast.ReturnStatement)
+		if got := ret.Value.ExprNode().Type.String(); got != want[i] {
+			t.Fatalf("literal %d: got type %s want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestLiteralMismatches(t *testing.T) {
+	expectCodes(t, `func a() i32 { return null; }`, diag.ErrReturnTypeMismatch)
+	expectCodes(t, `func a() u8 { return 'x'; }`, diag.ErrReturnTypeMismatch)
+	expectCodes(t, `func a() bool { return true + false; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func a() char { return -'x'; }`, diag.ErrInvalidOperandType)
+}
+
+func TestFloatConstants(t *testing.T) {
+	src := `
+func a() f64 { return -inf; }
+func b() f32 { return nanf; }
+func c() f64 { return infd * 2.0; }
+`
+	fsn, list := analyze(t, src)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	want := []string{"f64", "f32", "f64"}
+	for i, st := range fsn.Statements {
+		ret := st.(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body[0].(*ast.ReturnStatement)
+		if got := ret.Value.ExprNode().Type.String(); got != want[i] {
+			t.Fatalf("constant %d: got type %s want %s", i, got, want[i])
+		}
+	}
+
+	expectCodes(t, `func a() f32 { return inf; }`, diag.ErrReturnTypeMismatch)
+	expectCodes(t, `func a() { inf = 1.0; }`, diag.ErrNotAssignable)
+
+	// They are not keywords, declarations can reuse their names
+	expectCodes(t, `func nand(a bool, b bool) bool { return !(a && b); }`)
+	expectCodes(t, `func f() { let inf = 1i; let nan = inf + 1i; }`)
+}
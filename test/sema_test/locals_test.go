@@ -0,0 +1,53 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func TestVariableDeclarations(t *testing.T) {
+	fsn, list := analyze(t, `
+func main() i32 {
+    var a i32 = 2i;
+    let b = a * 3i;
+    var c i64;
+    var p ptr = null;
+    let ok = b > a && p == null;
+    return b;
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	body := fsn.Statements[0].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+
+	want := []string{"i32", "i32", "i64", "ptr", "bool"}
+	for i, w := range want {
+		vd := body[i].(*ast.VariableDeclaration)
+		if vd.VarType.String() != w {
+			t.Fatalf("%s: got type %s want %s", vd.Name.Identifier, vd.VarType, w)
+		}
+	}
+
+	if body[0].(*ast.VariableDeclaration).Mutable != true || body[1].(*ast.VariableDeclaration).Mutable != false {
+		t.Fatalf("wrong mutability")
+	}
+
+	// Uses resolve to their declaration
+	ret := body[5].(*ast.ReturnStatement).Value.(*ast.Identifier)
+	if ret.Decl != body[1] {
+		t.Fatalf("identifier resolved to %v, want the declaration of b", ret.Decl)
+	}
+}
+
+func TestVariableDeclarationErrors(t *testing.T) {
+	expectCodes(t, `func f() { let a i32; }`, diag.ErrMissingInitializer)
+	expectCodes(t, `func f() { var b; }`, diag.ErrMissingInitializer)
+	expectCodes(t, `func f() { var c = null; }`, diag.ErrCannotInferType)
+	expectCodes(t, `func f() { var d i32 = 1l; }`, diag.ErrTypeMismatch)
+	expectCodes(t, `func f() { var e = e; }`, diag.ErrUndefinedSymbol)
+	expectCodes(t, `func f() { let a = 1i; var a = 2i; }`, diag.ErrRedefinition)
+	expectCodes(t, `func f() { { let a = 1i; } let b = a; }`, diag.ErrUndefinedSymbol)
+	expectCodes(t, `func f() { var f = 1i; }`, diag.ErrRedefinition)
+}
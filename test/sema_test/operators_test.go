@@ -0,0 +1,77 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+// Returns the expression of the first return statement of the first function
+func firstReturn(fsn *ast.FileSourceNode) ast.Expression {
+	body := fsn.Statements[0].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement)
+	return body.Body[0].(*ast.ReturnStatement).Value
+}
+
+func TestComparisons(t *testing.T) {
+	fsn, list := analyze(t, `func a() bool { return 1i + 2i < 3i * 4i == true; }`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	// Arithmetic binds tighter than comparisons
+	eq := firstReturn(fsn).(*ast.Binary)
+	lt := eq.Left.(*ast.Binary)
+	if eq.Type.String() != "bool" || lt.Type.String() != "bool" || lt.Left.ExprNode().Type.String() != "i32" {
+		t.Fatalf("unexpected types: %s %s", eq.Type, lt.Type)
+	}
+
+	for _, src := range []string{
+		`func a() bool { return 1.5 != 2.0; }`,
+		`func a() bool { return 'a' == 'b'; }`,
+		`func a() bool { return null == null; }`,
+		`func a() bool { return 3ub >= 2ub; }`,
+	} {
+		if _, list := analyze(t, src); len(list) != 0 {
+			t.Fatalf("%s: unexpected diagnostics: %v", src, list)
+		}
+	}
+}
+
+func TestComparisonErrors(t *testing.T) {
+	expectCodes(t, `func a() bool { return 1i < 2l; }`, diag.ErrMismatchedOperands)
+	expectCodes(t, `func a() bool { return 1i == true; }`, diag.ErrMismatchedOperands)
+	expectCodes(t, `func a() bool { return true < false; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func a() bool { return 'a' <= 'b'; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func a() bool { return "a" == "b"; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func a() i32 { return 1i < 2i; }`, diag.ErrReturnTypeMismatch)
+}
+
+func TestLogicalAndBitwise(t *testing.T) {
+	for _, src := range []string{
+		`func a() bool { return !(1i < 2i) && 3i > 4i || !false; }`,
+		`func a() i32 { return ~1i & 7i | 8i ^ 3i << 2ub >> 1ul; }`,
+		`func a() bool { return 1i & 3i == 1i; }`,
+		`func a() u8 { return 1ub << 7ul; }`,
+	} {
+		if _, list := analyze(t, src); len(list) != 0 {
+			t.Fatalf("%s: unexpected diagnostics: %v", src, list)
+		}
+	}
+
+	// '&&' binds tighter than '||'
+	fsn, _ := analyze(t, `func a() bool { return true || false && false; }`)
+	or := firstReturn(fsn).(*ast.Binary)
+	if _, ok := or.Right.(*ast.Binary); !ok {
+		t.Fatalf("expected '&&' on the right of '||'")
+	}
+}
+
+func TestLogicalAndBitwiseErrors(t *testing.T) {
+	expectCodes(t, `func a() bool { return 1i && true; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func a() bool { return !1i; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func a() f32 { return ~1.0f; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func a() f64 { return 1.0 | 2.0; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func a() i32 { return 1i ^ 2l; }`, diag.ErrMismatchedOperands)
+	expectCodes(t, `func a() i32 { return 1i << 2i; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func a() bool { return true >> 1ub; }`, diag.ErrInvalidOperandType)
+}
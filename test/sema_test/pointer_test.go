@@ -0,0 +1,53 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func TestPointers(t *testing.T) {
+	fsn, list := analyze(t, `
+func main(raw ptr) i32 {
+    var n = 1i;
+    var p = &n;
+    var pp = &p;
+    *p = **pp + 1i;
+    var q *i32 = null;
+    let r = raw as *u8;
+    let s = p as ptr;
+    let t = q == null;
+    return *p;
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	body := fsn.Statements[0].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+	want := []string{"i32", "*i32", "**i32", "", "*i32", "*u8", "ptr", "bool"}
+	for i, w := range want {
+		if vd, ok := body[i].(*ast.VariableDeclaration); ok && vd.VarType.String() != w {
+			t.Fatalf("%s: got type %s want %s", vd.Name.Identifier, vd.VarType, w)
+		}
+	}
+
+	// Null takes the type of the pointer it is compared with
+	cmp := body[7].(*ast.VariableDeclaration).Value.(*ast.Binary)
+	if cmp.Right.ExprNode().Type.String() != "*i32" {
+		t.Fatalf("null has type %s, want *i32", cmp.Right.ExprNode().Type)
+	}
+}
+
+func TestPointerErrors(t *testing.T) {
+	expectCodes(t, `func f() { let n = 1i; let p = &n; }`, diag.ErrNotAddressable)
+	expectCodes(t, `func f(n i32) { let p = &n; }`, diag.ErrNotAddressable)
+	expectCodes(t, `func f() { var n = 1i; let p = &(n + 1i); }`, diag.ErrNotAddressable)
+	expectCodes(t, `func f() { let p = &f; }`, diag.ErrNotAddressable)
+	expectCodes(t, `func f(raw ptr) { let n = *raw; }`, diag.ErrInvalidDereference)
+	expectCodes(t, `func f(n i32) { let m = *n; }`, diag.ErrInvalidDereference)
+	expectCodes(t, `func f() { let m = *null; }`, diag.ErrInvalidDereference)
+	expectCodes(t, `func f(raw ptr) { var p *i32 = raw; }`, diag.ErrTypeMismatch)
+	expectCodes(t, `func f(p *i32, q *u8) { let b = p == q; }`, diag.ErrMismatchedOperands)
+	expectCodes(t, `func f(p *i32) { let b = p < p; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func f(p *Meters) { }`, diag.ErrUndefinedSymbol)
+}
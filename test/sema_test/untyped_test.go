@@ -0,0 +1,93 @@
+package sema_test
+
+import (
+	"fracta/internal/ast"
+	"fracta/internal/diag"
+	"testing"
+)
+
+func TestUntypedConstants(t *testing.T) {
+	fsn, list := analyze(t, `
+func half(x f32) f32 { return x / 2; }
+func main() i32 {
+    let a = 1;
+    let b = 2.5;
+    let c u8 = 255;
+    let d = c + 1;
+    let e = 1 << 62;
+    let f = -(1 + 2) * 3;
+    let g f32 = 1;
+    let h i32 = 4.0;
+    var i = half(3);
+    let j = 7 / 2;
+    let k = 7 / 2.0;
+    let l u64 = 1 << 63;
+    let m i8 = -128;
+    return 2;
+}`)
+	if len(list) != 0 {
+		t.Fatalf("unexpected diagnostics: %v", list)
+	}
+
+	body := fsn.Statements[1].(*ast.FunctionDeclaration).Body.(*ast.BlockStatement).Body
+
+	want := []struct {
+		typ   string
+		value string
+	}{
+		{"i64", "1"},
+		{"f64", "2.5"},
+		{"u8", "255"},
+		{"u8", ""},
+		{"i64", "4611686018427387904"},
+		{"i64", "-9"},
+		{"f32", "1"},
+		{"i32", "4"},
+		{"f32", ""},
+		{"i64", "3"},
+		{"f64", "3.5"},
+		{"u64", "9223372036854775808"},
+		{"i8", "-128"},
+	}
+	for i, w := range want {
+		vd := body[i].(*ast.VariableDeclaration)
+		if vd.VarType.String() != w.typ {
+			t.Fatalf("%s: got type %s want %s", vd.Name.Identifier, vd.VarType, w.typ)
+		}
+
+		c := vd.Value.ExprNode().Const
+		switch {
+		case w.value == "" && c != nil:
+			t.Fatalf("%s: unexpected constant %v", vd.Name.Identifier, c)
+		case w.value != "" && (c == nil || c.String() != w.value):
+			t.Fatalf("%s: got constant %v want %s", vd.Name.Identifier, c, w.value)
+		}
+	}
+
+	// The untyped operand takes the type of the other one
+	d := body[3].(*ast.VariableDeclaration).Value.(*ast.Binary)
+	if d.Right.ExprNode().Type.String() != "u8" {
+		t.Fatalf("1 in c + 1 has type %s, want u8", d.Right.ExprNode().Type)
+	}
+}
+
+func TestUntypedConstantErrors(t *testing.T) {
+	expectCodes(t, `func f() { let a u8 = 256; }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f() { let a u8 = -1; }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f() { let a i32 = 2.5; }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f() { let a = 1 << 63; }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f() { let a = 9223372036854775808; }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f() { let a f32 = 1e39; }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f() i8 { return 200; }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f(x u8) { f(300); }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f() { var a u8 = 0; a = 1000; }`, diag.ErrConstantNotRepresentable)
+	expectCodes(t, `func f() { let a = 1 / 0; }`, diag.ErrDivisionByZero)
+	expectCodes(t, `func f() { let a = 1 << -1; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func f() { let a = 1.5 & 1; }`, diag.ErrInvalidOperandType)
+	expectCodes(t, `func f() { let a bool = 1; }`, diag.ErrTypeMismatch)
+	expectCodes(t, `func f() { if 1 { } }`, diag.ErrNonBoolCondition)
+
+	// Suffixes still force the type
+	expectCodes(t, `func f() { let a i32 = 1l; }`, diag.ErrTypeMismatch)
+	expectCodes(t, `func f() { let a = 1i + 1l; }`, diag.ErrMismatchedOperands)
+}
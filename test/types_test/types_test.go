@@ -0,0 +1,120 @@
+package types_test
+
+import (
+	"fracta/internal/types"
+	"testing"
+)
+
+func TestInterning(t *testing.T) {
+	if types.NewPointer(types.I32) != types.NewPointer(types.I32) {
+		t.Fatalf("pointer types are not canonical")
+	}
+	if types.NewArray(types.U8, 4) != types.NewArray(types.U8, 4) || types.NewArray(types.U8, 4) == types.NewArray(types.U8, 5) {
+		t.Fatalf("array types are not canonical")
+	}
+	if types.NewSlice(types.I32) != types.NewSlice(types.I32) || types.NewSlice(types.I32).String() != "[]i32" {
+		t.Fatalf("slice types are not canonical")
+	}
+
+	f := types.NewFunction([]types.Type{types.I32, types.NewPointer(types.U8)}, types.Bool)
+	if f != types.NewFunction([]types.Type{types.I32, types.NewPointer(types.U8)}, types.Bool) {
+		t.Fatalf("function types are not canonical")
+	}
+	if f.String() != "func(i32, *u8) bool" {
+		t.Fatalf("got %q", f.String())
+	}
+	if s := types.NewFunction(nil, types.Void).String(); s != "func()" {
+		t.Fatalf("got %q", s)
+	}
+
+	fields := []types.Field{{Name: "x", Type: types.I32}, {Name: "y", Type: types.I32}}
+	if types.NewStruct(fields) != types.NewStruct(fields) {
+		t.Fatalf("struct types are not canonical")
+	}
+
+	a := types.NewNamed("Meters", types.F64)
+	b := types.NewNamed("Meters", types.F64)
+	if types.Identical(a, b) || !types.IdenticalUnderlying(a, b) {
+		t.Fatalf("named types must be distinct but share their underlying type")
+	}
+}
+
+func TestLayout(t *testing.T) {
+	s := types.NewStruct([]types.Field{
+		{Name: "a", Type: types.U8},
+		{Name: "b", Type: types.I64},
+		{Name: "c", Type: types.U16},
+	})
+	if types.Size(s) != 24 || types.Align(s) != 8 {
+		t.Fatalf("got size %d align %d", types.Size(s), types.Align(s))
+	}
+
+	arr := types.NewArray(types.I32, 3)
+	if types.Size(arr) != 12 || types.Align(arr) != 4 {
+		t.Fatalf("got size %d align %d", types.Size(arr), types.Align(arr))
+	}
+	if types.Size(types.NewPointer(arr)) != types.PointerSize {
+		t.Fatalf("wrong pointer size")
+	}
+	if types.Size(types.NewSlice(arr)) != 16 || types.Align(types.NewSlice(arr)) != 8 {
+		t.Fatalf("slices are a pointer and a length")
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	named := types.NewNamed("Id", types.U32)
+
+	switch {
+	case !types.IsInteger(types.I8) || !types.IsSigned(types.I8) || types.IsUnsigned(types.I8):
+		t.Fatalf("i8")
+	case !types.IsInteger(named) || !types.IsUnsigned(named):
+		t.Fatalf("named types use the predicates of their underlying type")
+	case types.IsInteger(types.UntypedInt) || !types.IsUntyped(types.UntypedInt):
+		t.Fatalf("untyped int")
+	case !types.IsFloat(types.F32) || types.IsInteger(types.F32):
+		t.Fatalf("f32")
+	case !types.IsPointer(types.Ptr) || !types.IsPointer(types.NewPointer(types.Char)):
+		t.Fatalf("pointers")
+	case !types.AssignableTo(types.UntypedNull, types.NewPointer(types.I32)) || types.AssignableTo(types.UntypedNull, types.I32):
+		t.Fatalf("null")
+	case types.Default(types.UntypedFloat) != types.F64:
+		t.Fatalf("default type")
+	}
+}
+
+func TestCastClassification(t *testing.T) {
+	cases := []struct {
+		from, to types.Type
+		want     types.CastKind
+	}{
+		{types.I32, types.I32, types.CastNone},
+		{types.I32, types.U8, types.CastIntResize},
+		{types.U8, types.I64, types.CastIntResize},
+		{types.Bool, types.U32, types.CastIntResize},
+		{types.Char, types.U32, types.CastIntResize},
+		{types.U32, types.Char, types.CastIntResize},
+		{types.I64, types.F32, types.CastIntToFloat},
+		{types.F64, types.U16, types.CastFloatToInt},
+		{types.F32, types.F64, types.CastFloatResize},
+		{types.U64, types.Ptr, types.CastIntToPtr},
+		{types.Ptr, types.I64, types.CastPtrToInt},
+		{types.UntypedNull, types.Ptr, types.CastPtrToPtr},
+		{types.Ptr, types.NewPointer(types.I32), types.CastPtrToPtr},
+		{types.NewPointer(types.I32), types.Ptr, types.CastPtrToPtr},
+		{types.UntypedNull, types.NewPointer(types.I32), types.CastPtrToPtr},
+		{types.NewPointer(types.I32), types.NewPointer(types.F64), types.CastInvalid},
+		{types.NewPointer(types.U8), types.U64, types.CastPtrToInt},
+
+		{types.I32, types.Bool, types.CastInvalid},
+		{types.F32, types.Char, types.CastInvalid},
+		{types.Ptr, types.F64, types.CastInvalid},
+		{types.Str, types.I64, types.CastInvalid},
+		{types.NewFunction(nil, types.Void), types.F32, types.CastInvalid},
+	}
+
+	for _, c := range cases {
+		if got := types.ClassifyCast(c.from, c.to); got != c.want {
+			t.Fatalf("%s as %s: got %d want %d", c.from, c.to, got, c.want)
+		}
+	}
+}